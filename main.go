@@ -3,6 +3,7 @@ package main
 import (
 	"OptiOJ/src/config"
 	"OptiOJ/src/routes"
+	"OptiOJ/src/services"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -20,8 +21,19 @@ func main() {
 	config.InitDB()
 	config.InitRedis()
 
+	// 启动作业定时发布任务
+	services.StartAssignmentPublishScheduler()
+
+	// 启动 JWT 签名密钥定时轮换任务
+	config.StartJWTKeyRotation()
+
 	r := gin.Default()
 
+	// 只信任配置的反向代理传递的客户端 IP，防止伪造 X-Forwarded-For 绕过按 IP 的登录限制
+	if err := r.SetTrustedProxies(config.Server.TrustedProxies); err != nil {
+		logrus.Fatal("受信任代理配置无效: ", err)
+	}
+
 	// 配置 CORS 规则
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},                                       // 允许所有来源
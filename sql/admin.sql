@@ -9,3 +9,24 @@ CREATE TABLE admins (
 );
 
 CREATE INDEX idx_admin_role ON admins(role);
+
+-- 网站自定义角色表，也用于覆盖内置角色（super_admin 除外）的默认权限
+CREATE TABLE site_roles (
+    role VARCHAR(20) NOT NULL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    permissions VARCHAR(512) NOT NULL DEFAULT '', -- 逗号分隔的权限列表
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- 用户网站角色分配表（super_admin 和 admin 仍由 admins 表记录）
+CREATE TABLE user_site_roles (
+    user_id BIGINT UNSIGNED NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    granted_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_user_site_roles_role ON user_site_roles(role);
@@ -29,8 +29,37 @@ CREATE TABLE judge_results (
     FOREIGN KEY (test_case_id) REFERENCES test_cases(id)
 );
 
+-- 作业提交批阅表（人工评分）
+CREATE TABLE submission_reviews (
+    submission_id BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+    reviewer_id BIGINT UNSIGNED NOT NULL,
+    manual_score INT,               -- 人工评分，为空表示沿用自动评分
+    rationale TEXT,                 -- 评分理由
+    reviewed BOOLEAN NOT NULL DEFAULT false, -- 是否已批阅
+    reviewed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (submission_id) REFERENCES submissions(id),
+    FOREIGN KEY (reviewer_id) REFERENCES users(id)
+);
+
+-- 作业提交代码行评论表
+CREATE TABLE submission_comments (
+    id SERIAL PRIMARY KEY,
+    submission_id BIGINT UNSIGNED NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    line_start INT NOT NULL,        -- 起始行号（从1开始）
+    line_end INT NOT NULL,          -- 结束行号
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (submission_id) REFERENCES submissions(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
 -- 创建索引
 CREATE INDEX idx_submissions_problem_id ON submissions(problem_id);
 CREATE INDEX idx_submissions_user_id ON submissions(user_id);
 CREATE INDEX idx_submissions_status ON submissions(status);
-CREATE INDEX idx_judge_results_submission_id ON judge_results(submission_id); 
\ No newline at end of file
+CREATE INDEX idx_judge_results_submission_id ON judge_results(submission_id);
+CREATE INDEX idx_submission_comments_submission_id ON submission_comments(submission_id);
@@ -4,7 +4,7 @@ CREATE TABLE user_logins (
     login_time TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     ip_address VARCHAR(45) NOT NULL,  -- IPv6 地址最长 45 字符
     user_agent TEXT,                  -- 记录用户浏览器信息
-    login_status VARCHAR(20) NOT NULL, -- success, failed, blocked 等
+    login_status VARCHAR(20) NOT NULL, -- success, failed, blocked, locked, password_reset, reset_failed 等
     fail_reason TEXT,                 -- 登录失败原因
     location VARCHAR(100),            -- 登录地理位置（可选）
     FOREIGN KEY (user_id) REFERENCES users(id)
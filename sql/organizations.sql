@@ -0,0 +1,35 @@
+-- 组织表（学校、年级、班级），按 parent_id 构成树形结构，团队挂在组织下
+CREATE TABLE organizations (
+    id SERIAL PRIMARY KEY,
+    parent_id BIGINT UNSIGNED NULL,
+    name VARCHAR(100) NOT NULL,
+    type ENUM('school', 'grade', 'class', 'other') NOT NULL DEFAULT 'other',
+    description TEXT,
+    created_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (parent_id) REFERENCES organizations(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+-- 组织管理员表，组织管理员可以管理该组织及其下级组织中的所有团队
+CREATE TABLE organization_admins (
+    org_id BIGINT UNSIGNED NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, user_id),
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- 组织题库表，组织题库中的题目对下级所有团队可见
+CREATE TABLE organization_problems (
+    org_id BIGINT UNSIGNED NOT NULL,
+    problem_id BIGINT UNSIGNED NOT NULL,
+    added_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, problem_id),
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (problem_id) REFERENCES problems(id),
+    FOREIGN KEY (added_by) REFERENCES users(id)
+);
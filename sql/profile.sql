@@ -4,9 +4,12 @@ CREATE TABLE profiles (
     bio VARCHAR(255),
     gender VARCHAR(10),
     school VARCHAR(100),
+    school_org_id BIGINT UNSIGNED NULL,            -- 关联的学校组织（organizations 表在 profiles 之后创建，不设外键）
+    school_verified BOOLEAN NOT NULL DEFAULT false, -- 学校关联是否已由组织管理员认证
     birthday TIMESTAMP NULL,
     location VARCHAR(100),
     real_name VARCHAR(50),
+    student_id VARCHAR(50),                        -- 学号
     create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     update_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id),
@@ -3,22 +3,54 @@ CREATE TABLE teams (
     name VARCHAR(100) NOT NULL,
     description TEXT,
     avatar VARCHAR(255),
+    org_id BIGINT UNSIGNED NULL,         -- 所属组织，NULL 表示不属于任何组织
     created_by BIGINT UNSIGNED NOT NULL,
+    archived_at TIMESTAMP NULL,          -- 归档时间，NULL 表示未归档；归档后团队只读
+    archived_by BIGINT UNSIGNED NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    FOREIGN KEY (created_by) REFERENCES users(id)
+    FOREIGN KEY (org_id) REFERENCES organizations(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (archived_by) REFERENCES users(id)
+);
+
+-- 团队所有权转让表，需接收方确认
+CREATE TABLE team_ownership_transfers (
+    id SERIAL PRIMARY KEY,
+    team_id BIGINT UNSIGNED NOT NULL,
+    from_user_id BIGINT UNSIGNED NOT NULL,
+    to_user_id BIGINT UNSIGNED NOT NULL,
+    status ENUM('pending', 'accepted', 'rejected', 'cancelled') NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (from_user_id) REFERENCES users(id),
+    FOREIGN KEY (to_user_id) REFERENCES users(id)
 );
 
 CREATE TABLE team_members (
     team_id BIGINT UNSIGNED NOT NULL,
     user_id BIGINT UNSIGNED NOT NULL,
-    role ENUM('owner', 'admin', 'member') NOT NULL DEFAULT 'member',
+    role VARCHAR(20) NOT NULL DEFAULT 'member', -- 内置角色：owner, admin, assistant, member, observer，或团队自定义角色
+    invitation_id BIGINT UNSIGNED NULL,         -- 加入时使用的邀请（team_invitations 在之后创建，不设外键）
     joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (team_id, user_id),
     FOREIGN KEY (team_id) REFERENCES teams(id),
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
 
+-- 团队自定义角色表，也用于覆盖内置角色（owner 除外）的默认权限
+CREATE TABLE team_roles (
+    team_id BIGINT UNSIGNED NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    permissions VARCHAR(255) NOT NULL DEFAULT '', -- 逗号分隔的权限列表
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, role),
+    FOREIGN KEY (team_id) REFERENCES teams(id)
+);
+
 CREATE TABLE team_assignments (
     id SERIAL PRIMARY KEY,
     team_id BIGINT UNSIGNED NOT NULL,
@@ -26,6 +58,12 @@ CREATE TABLE team_assignments (
     description TEXT,
     start_time TIMESTAMP NOT NULL,
     end_time TIMESTAMP NOT NULL,
+    allow_late BOOLEAN NOT NULL DEFAULT false,            -- 是否允许迟交
+    late_penalty_percent INT NOT NULL DEFAULT 0,          -- 每个计时单位扣除的分数百分比
+    late_penalty_unit ENUM('hour', 'day') NOT NULL DEFAULT 'day', -- 迟交扣分计时单位
+    late_cutoff TIMESTAMP NULL,                           -- 迟交截止时间，NULL 表示不限
+    is_published BOOLEAN NOT NULL DEFAULT true,           -- 是否已发布，未发布的作业对普通成员不可见
+    publish_at TIMESTAMP NULL,                            -- 定时发布时间，NULL 表示不定时
     created_by BIGINT UNSIGNED NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -33,6 +71,21 @@ CREATE TABLE team_assignments (
     FOREIGN KEY (created_by) REFERENCES users(id)
 );
 
+-- 作业截止时间延期表（按成员）
+CREATE TABLE team_assignment_extensions (
+    assignment_id BIGINT UNSIGNED NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    end_time TIMESTAMP NOT NULL,        -- 延期后的截止时间
+    reason VARCHAR(255),
+    granted_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (assignment_id, user_id),
+    FOREIGN KEY (assignment_id) REFERENCES team_assignments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (granted_by) REFERENCES users(id)
+);
+
 CREATE TABLE team_assignment_problems (
     assignment_id BIGINT UNSIGNED NOT NULL,
     problem_id BIGINT UNSIGNED NOT NULL,
@@ -46,6 +99,36 @@ CREATE TABLE team_assignment_problems (
     FOREIGN KEY (team_problem_id) REFERENCES team_problems(id)
 );
 
+-- 作业模板表，时间以相对开始时间的分钟数保存
+CREATE TABLE team_assignment_templates (
+    id SERIAL PRIMARY KEY,
+    team_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    duration_minutes INT NOT NULL,                         -- 作业持续时间（分钟）
+    allow_late BOOLEAN NOT NULL DEFAULT false,
+    late_penalty_percent INT NOT NULL DEFAULT 0,
+    late_penalty_unit ENUM('hour', 'day') NOT NULL DEFAULT 'day',
+    late_cutoff_minutes INT NULL,                          -- 迟交截止时间相对结束时间的分钟数，NULL 表示不限
+    created_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE TABLE team_assignment_template_problems (
+    template_id BIGINT UNSIGNED NOT NULL,
+    problem_id BIGINT UNSIGNED NOT NULL,
+    order_index INT NOT NULL,
+    score INT NOT NULL DEFAULT 100,
+    problem_type ENUM('global', 'team') NOT NULL DEFAULT 'global',
+    team_problem_id BIGINT UNSIGNED NULL,
+    PRIMARY KEY (template_id, problem_id),
+    FOREIGN KEY (template_id) REFERENCES team_assignment_templates(id) ON DELETE CASCADE
+);
+
 CREATE TABLE team_problem_lists (
     id SERIAL PRIMARY KEY,
     team_id BIGINT UNSIGNED NOT NULL,
@@ -74,6 +157,11 @@ CREATE TABLE team_invitations (
     team_id BIGINT UNSIGNED NOT NULL,
     code VARCHAR(20) NOT NULL,
     expires_at TIMESTAMP NOT NULL,
+    max_uses INT NULL,                            -- 最大使用次数，NULL 表示不限
+    used_count INT NOT NULL DEFAULT 0,            -- 已使用次数
+    role VARCHAR(20) NOT NULL DEFAULT 'member',   -- 通过该邀请加入后的角色
+    email VARCHAR(100) NOT NULL DEFAULT '',       -- 限定可使用的邮箱，空表示不限
+    revoked_at TIMESTAMP NULL,                    -- 撤销时间
     created_by BIGINT UNSIGNED NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     UNIQUE (team_id, code),
@@ -115,6 +203,7 @@ CREATE TABLE team_problems (
     hint TEXT,
     time_limit INT NOT NULL DEFAULT 1000,
     memory_limit INT NOT NULL DEFAULT 256,
+    copied_from BIGINT UNSIGNED NULL,   -- 复制来源的团队题目，原题删除后保留该值
     created_by BIGINT UNSIGNED NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -133,6 +222,53 @@ CREATE TABLE team_problem_testcases (
     FOREIGN KEY (problem_id) REFERENCES team_problems(id)
 );
 
+-- 团队私有题目共享表，readonly 只能查看，copy 还可以复制到本团队
+CREATE TABLE team_problem_shares (
+    problem_id BIGINT UNSIGNED NOT NULL,
+    team_id BIGINT UNSIGNED NOT NULL,             -- 被共享的团队
+    mode ENUM('readonly', 'copy') NOT NULL DEFAULT 'readonly',
+    shared_by BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (problem_id, team_id),
+    FOREIGN KEY (problem_id) REFERENCES team_problems(id),
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (shared_by) REFERENCES users(id)
+);
+
+-- 团队私有题目推荐到公共题库的申请表
+CREATE TABLE team_problem_promotions (
+    id SERIAL PRIMARY KEY,
+    team_problem_id BIGINT UNSIGNED NOT NULL,
+    team_id BIGINT UNSIGNED NOT NULL,
+    requested_by BIGINT UNSIGNED NOT NULL,
+    note TEXT,                                    -- 推荐理由
+    status ENUM('pending', 'approved', 'rejected') NOT NULL DEFAULT 'pending',
+    reviewed_by BIGINT UNSIGNED NULL,
+    review_note TEXT,
+    reviewed_at TIMESTAMP NULL,
+    problem_id BIGINT UNSIGNED NULL,              -- 审核通过后生成的公共题目
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_problem_id) REFERENCES team_problems(id),
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (requested_by) REFERENCES users(id),
+    FOREIGN KEY (reviewed_by) REFERENCES users(id),
+    FOREIGN KEY (problem_id) REFERENCES problems(id)
+);
+
+-- 团队动态表，记录成员加入、作业发布、提交、通过和题单变更等事件
+CREATE TABLE team_events (
+    id SERIAL PRIMARY KEY,
+    team_id BIGINT UNSIGNED NOT NULL,
+    actor_id BIGINT UNSIGNED NULL,                -- 触发事件的用户，定时任务触发时为空
+    type VARCHAR(30) NOT NULL,                    -- 事件类型：member_joined, assignment_published, submission 等
+    target_id BIGINT UNSIGNED NULL,               -- 关联对象ID：成员、作业、提交或题单
+    summary VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (actor_id) REFERENCES users(id)
+);
+
 -- 创建索引
 CREATE INDEX idx_team_members_user_id ON team_members(user_id);
 CREATE INDEX idx_team_assignments_team_id ON team_assignments(team_id);
@@ -140,3 +276,8 @@ CREATE INDEX idx_team_problem_lists_team_id ON team_problem_lists(team_id);
 CREATE INDEX idx_team_invitations_code ON team_invitations(code);
 CREATE INDEX idx_team_problems_team_id ON team_problems(team_id);
 CREATE INDEX idx_team_problem_testcases_problem_id ON team_problem_testcases(problem_id);
+CREATE INDEX idx_team_problem_shares_team_id ON team_problem_shares(team_id);
+CREATE INDEX idx_team_problem_promotions_status ON team_problem_promotions(status);
+CREATE INDEX idx_team_assignments_publish_at ON team_assignments(is_published, publish_at);
+CREATE INDEX idx_team_assignment_templates_team_id ON team_assignment_templates(team_id);
+CREATE INDEX idx_team_events_team_id ON team_events(team_id, created_at);
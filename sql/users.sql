@@ -4,6 +4,7 @@ CREATE TABLE users (
     password TEXT NOT NULL,
     phone VARCHAR(15) UNIQUE,
     email VARCHAR(100) UNIQUE,
+    must_change_password BOOLEAN NOT NULL DEFAULT false, -- 首次登录需修改密码（批量生成或导入的账号）
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
@@ -17,3 +18,57 @@ CREATE TABLE avatars (
     UNIQUE KEY (user_id),
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
+
+CREATE TABLE user_two_factors (
+    user_id BIGINT UNSIGNED NOT NULL,
+    secret VARCHAR(64) NOT NULL, -- base32 编码的 TOTP 密钥
+    enabled BOOLEAN NOT NULL DEFAULT false, -- 提交验证码完成绑定后才启用
+    enabled_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE user_recovery_codes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    code_hash CHAR(64) NOT NULL, -- 恢复码的 SHA-256 摘要
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_user_recovery_codes_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE personal_access_tokens (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    token_hash CHAR(64) NOT NULL, -- 令牌的 SHA-256 摘要
+    token_prefix VARCHAR(16) NOT NULL, -- 令牌开头几位，便于用户辨认
+    scopes VARCHAR(255) NOT NULL, -- 逗号分隔的权限范围：submit, submission.read, problem.read
+    expires_at TIMESTAMP NULL, -- 为空表示永不过期
+    last_used_at TIMESTAMP NULL,
+    last_used_ip VARCHAR(45) NOT NULL DEFAULT '',
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_personal_access_tokens_hash (token_hash),
+    INDEX idx_personal_access_tokens_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE user_identities (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    provider VARCHAR(255) NOT NULL, -- 身份提供方的 issuer
+    subject VARCHAR(255) NOT NULL, -- 身份提供方中的用户标识
+    email VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_user_identities_subject (provider, subject),
+    INDEX idx_user_identities_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
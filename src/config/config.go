@@ -3,7 +3,6 @@ package config
 import (
 	"OptiOJ/src/models"
 	"context"
-	"crypto/rand"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -24,6 +23,15 @@ type Config struct {
 	Aliyun   AliyunConfig
 	Geetest  GeetestConfig
 	Judge    JudgeConfig
+	JWT      JWTConfig
+	OIDC     OIDCConfig `toml:"oidc"`
+	Server   ServerConfig
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	// 受信任的反向代理地址或网段，只有来自这些地址的请求才读取 X-Forwarded-For 作为客户端 IP；为空时不信任任何代理
+	TrustedProxies []string `toml:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -68,15 +76,47 @@ type JudgeConfig struct {
 	Port int
 }
 
+// OIDCConfig 单点登录配置，支持 OpenID Connect 和只提供用户信息接口的 OAuth2 服务
+type OIDCConfig struct {
+	Enabled      bool     `toml:"enabled"`
+	Name         string   `toml:"name"`   // 登录按钮上显示的名称
+	Issuer       string   `toml:"issuer"` // 用于获取 /.well-known/openid-configuration 并校验 ID Token 的 iss
+	ClientID     string   `toml:"client_id"`
+	ClientSecret string   `toml:"client_secret"`
+	RedirectURL  string   `toml:"redirect_url"` // 前端回调页面地址，需要在身份提供方登记
+	Scopes       []string `toml:"scopes"`
+
+	// 以下端点为空时从发现文档获取；OAuth2 服务没有发现文档时需要手动配置
+	AuthorizationEndpoint string `toml:"authorization_endpoint"`
+	TokenEndpoint         string `toml:"token_endpoint"`
+	UserInfoEndpoint      string `toml:"userinfo_endpoint"`
+	JWKSURI               string `toml:"jwks_uri"`
+
+	AutoProvision bool `toml:"auto_provision"` // 没有匹配的账号时自动创建
+	// 允许按身份提供方未确认的邮箱关联已有账号，默认不允许；管理员账号始终不会自动关联
+	AllowUnverifiedEmailLink bool `toml:"allow_unverified_email_link"`
+
+	Claims OIDCClaimMapping `toml:"claims"`
+}
+
+// OIDCClaimMapping 身份提供方返回的声明名称
+type OIDCClaimMapping struct {
+	Subject       string `toml:"subject"`
+	Email         string `toml:"email"`
+	EmailVerified string `toml:"email_verified"`
+	Username      string `toml:"username"`
+}
+
 var DB *gorm.DB
 var SMTP SMTPConfig
 var Aliyun AliyunConfig
 var Geetest GeetestConfig
 var Judge JudgeConfig
+var OIDC OIDCConfig
+var Server ServerConfig
 var RedisClient *redis.Client
 var logger = logrus.New()
 var ctx = context.Background()
-var JWTSecret []byte
 
 func CheckAndInitializeDatabase() {
 	// 检查数据库是否需要初始化
@@ -108,16 +148,17 @@ func CheckAndInitializeDatabase() {
 
 		// 定义表的依赖关系和执行顺序
 		sqlFileOrder := []string{
-			"users.sql",        // 基础表，无依赖
-			"profile.sql",      // 依赖 users
-			"admin.sql",        // 依赖 users
-			"banned.sql",       // 依赖 users
-			"loginHistory.sql", // 依赖 users
-			"problems.sql",     // 基础表，无依赖
-			"teams.sql",        // 依赖 users, problems
-			"judge.sql",        // 依赖 users, problems
-			"messages.sql",     // 依赖 users
-			"tags.sql",         // 依赖 problems
+			"users.sql",         // 基础表，无依赖
+			"profile.sql",       // 依赖 users
+			"admin.sql",         // 依赖 users
+			"banned.sql",        // 依赖 users
+			"loginHistory.sql",  // 依赖 users
+			"problems.sql",      // 基础表，无依赖
+			"organizations.sql", // 依赖 users, problems
+			"teams.sql",         // 依赖 users, problems, organizations
+			"judge.sql",         // 依赖 users, problems
+			"messages.sql",      // 依赖 users
+			"tags.sql",          // 依赖 problems
 		}
 
 		// 创建文件名到路径的映射
@@ -296,34 +337,10 @@ func InitConfig() {
 	Aliyun = config.Aliyun
 	Geetest = config.Geetest
 	Judge = config.Judge
+	JWT = config.JWT
+	OIDC = config.OIDC
+	Server = config.Server
 
 	// 初始化 JWT 密钥
-	InitJWTSecret()
-}
-
-func InitJWTSecret() {
-	keyFile := "jwtKey"
-
-	// 尝试读取现有的 key 文件
-	content, err := os.ReadFile(keyFile)
-	if err == nil && len(content) > 0 {
-		// 如果文件存在且不为空，使用现有密钥
-		JWTSecret = content
-		logger.Info("JWT密钥加载成功")
-		return
-	}
-
-	// 如果文件不存在或为空，生成新的密钥
-	secret := make([]byte, 32)
-	if _, err := rand.Read(secret); err != nil {
-		logger.Fatal("生成JWT密钥失败:", err)
-	}
-
-	// 将密钥写入文件
-	if err := os.WriteFile(keyFile, secret, 0600); err != nil {
-		logger.Fatal("写入密钥文件失败:", err)
-	}
-
-	JWTSecret = secret
-	logger.Info("已生成新的JWT密钥并保存到key文件")
+	InitJWTKeys()
 }
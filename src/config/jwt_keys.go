@@ -0,0 +1,467 @@
+package config
+
+import (
+	"OptiOJ/src/models"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// JWT 签名算法
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
+// legacyJWTKeyID 旧版 jwtKey 文件中的密钥，用于验证升级前签发、不带 kid 的令牌
+const legacyJWTKeyID = "legacy"
+
+const (
+	jwtKeySetFile    = "jwtKeys.json"
+	legacyJWTKeyFile = "jwtKey"
+)
+
+// JWKSCacheMaxAge /.well-known/jwks.json 允许其他服务缓存的时间。
+// 轮换时新密钥至少提前这么久发布到 JWKS，保证缓存了旧 JWKS 的服务也能验证新密钥签发的令牌
+const JWKSCacheMaxAge = time.Hour
+
+type JWTConfig struct {
+	Algorithm    string `toml:"algorithm"`
+	RotationDays int    `toml:"rotation_days"`
+	GraceDays    int    `toml:"grace_days"`
+}
+
+// JWTKey 签名密钥。同一时间只有一个密钥用于签名，已退役的密钥在宽限期内仍可用于验证；
+// 预发布的密钥先出现在 JWKS 中，到 ActivatesAt 后才开始签名
+type JWTKey struct {
+	ID          string
+	Algorithm   string
+	CreatedAt   time.Time
+	ActivatesAt *time.Time
+	RetiredAt   *time.Time
+
+	signingKey interface{}
+	verifyKey  interface{}
+}
+
+// jwtKeyRecord 密钥在 jwtKeys.json 中的存储格式
+type jwtKeyRecord struct {
+	ID          string     `json:"kid"`
+	Algorithm   string     `json:"alg"`
+	Key         string     `json:"key"` // HS256 为 base64 编码的密钥，RS256/EdDSA 为 PKCS#8 PEM 私钥
+	CreatedAt   time.Time  `json:"created_at"`
+	ActivatesAt *time.Time `json:"activates_at,omitempty"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+}
+
+var (
+	JWT        JWTConfig
+	jwtKeys    []*JWTKey
+	jwtKeysMux sync.RWMutex
+)
+
+// SigningMethod 密钥对应的 JWT 签名方法
+func (k *JWTKey) SigningMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case JWTAlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case JWTAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// SigningKey 用于签名的密钥
+func (k *JWTKey) SigningKey() interface{} {
+	return k.signingKey
+}
+
+// VerifyKey 用于验证签名的密钥，非对称算法为公钥
+func (k *JWTKey) VerifyKey() interface{} {
+	return k.verifyKey
+}
+
+// activeFrom 密钥开始用于签名的时间，轮换周期从这一时间开始计算
+func (k *JWTKey) activeFrom() time.Time {
+	if k.ActivatesAt != nil {
+		return *k.ActivatesAt
+	}
+	return k.CreatedAt
+}
+
+// InitJWTKeys 加载签名密钥集，必要时迁移旧版密钥文件或立即轮换
+func InitJWTKeys() {
+	if JWT.Algorithm == "" {
+		JWT.Algorithm = JWTAlgorithmHS256
+	}
+	if JWT.Algorithm != JWTAlgorithmHS256 && JWT.Algorithm != JWTAlgorithmRS256 && JWT.Algorithm != JWTAlgorithmEdDSA {
+		logger.Fatalf("不支持的JWT签名算法: %s", JWT.Algorithm)
+	}
+	if JWT.GraceDays <= 0 {
+		// 默认宽限期略长于刷新令牌的有效期，保证轮换前签发的令牌都能正常使用到过期
+		JWT.GraceDays = 31
+	}
+
+	jwtKeysMux.Lock()
+	defer jwtKeysMux.Unlock()
+
+	keys, err := loadJWTKeys()
+	if err != nil {
+		logger.Fatal("加载JWT密钥失败:", err)
+	}
+	if keys == nil {
+		keys, err = migrateLegacyJWTKey()
+		if err != nil {
+			logger.Fatal("迁移旧版JWT密钥失败:", err)
+		}
+	}
+	jwtKeys = keys
+
+	if rotateJWTKeysIfNeeded(time.Now()) {
+		logger.Info("JWT签名密钥集已更新")
+	}
+	current := currentJWTKeyLocked(time.Now())
+	if current == nil {
+		logger.Fatal("没有可用的JWT签名密钥")
+	}
+	logger.Infof("JWT密钥加载成功，当前签名算法 %s，密钥 %s", current.Algorithm, current.ID)
+}
+
+// StartJWTKeyRotation 启动签名密钥的定时轮换任务
+func StartJWTKeyRotation() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			jwtKeysMux.Lock()
+			if rotateJWTKeysIfNeeded(now) {
+				logger.Info("JWT签名密钥集已按计划更新")
+			}
+			jwtKeysMux.Unlock()
+		}
+	}()
+}
+
+// CurrentJWTKey 获取当前用于签名的密钥
+func CurrentJWTKey() *JWTKey {
+	jwtKeysMux.RLock()
+	defer jwtKeysMux.RUnlock()
+	return currentJWTKeyLocked(time.Now())
+}
+
+// FindJWTKey 根据 kid 查找可用于验证的密钥，未携带 kid 的令牌使用旧版密钥验证
+func FindJWTKey(kid string) (*JWTKey, error) {
+	if kid == "" {
+		kid = legacyJWTKeyID
+	}
+
+	jwtKeysMux.RLock()
+	defer jwtKeysMux.RUnlock()
+	for _, key := range jwtKeys {
+		if key.ID == kid {
+			return key, nil
+		}
+	}
+	return nil, errors.New("未知的签名密钥")
+}
+
+// PublicJWKS 获取当前可用于验证的非对称公钥，供其他服务验证 OptiOJ 签发的令牌
+func PublicJWKS() models.JSONWebKeySet {
+	jwtKeysMux.RLock()
+	defer jwtKeysMux.RUnlock()
+
+	set := models.JSONWebKeySet{Keys: make([]models.JSONWebKey, 0)}
+	for _, key := range jwtKeys {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, models.JSONWebKey{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, models.JSONWebKey{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
+
+// currentJWTKeyLocked 当前签名密钥为最新的已生效且未退役的密钥，调用方需持有锁
+func currentJWTKeyLocked(now time.Time) *JWTKey {
+	for i := len(jwtKeys) - 1; i >= 0; i-- {
+		key := jwtKeys[i]
+		if key.RetiredAt == nil && !now.Before(key.activeFrom()) {
+			return key
+		}
+	}
+	return nil
+}
+
+// pendingJWTKeyLocked 已预发布但尚未生效的密钥，调用方需持有锁
+func pendingJWTKeyLocked(now time.Time) *JWTKey {
+	for i := len(jwtKeys) - 1; i >= 0; i-- {
+		key := jwtKeys[i]
+		if key.RetiredAt == nil && now.Before(key.activeFrom()) {
+			return key
+		}
+	}
+	return nil
+}
+
+// rotateJWTKeysIfNeeded 维护签名密钥集，调用方需持有写锁，返回密钥集是否发生了变化：
+//   - 没有可用密钥时立即生成并启用新密钥；
+//   - 算法变更或当前密钥即将到达轮换周期时预发布新密钥，至少 JWKSCacheMaxAge 之后才开始签名；
+//   - 新密钥生效后退役之前的密钥，并清理宽限期已过的旧密钥
+func rotateJWTKeysIfNeeded(now time.Time) bool {
+	changed := false
+	current := currentJWTKeyLocked(now)
+	pending := pendingJWTKeyLocked(now)
+
+	grace := time.Duration(JWT.GraceDays) * 24 * time.Hour
+	kept := make([]*JWTKey, 0, len(jwtKeys)+1)
+	for _, key := range jwtKeys {
+		if key.RetiredAt != nil && now.Sub(*key.RetiredAt) >= grace {
+			changed = true
+			continue
+		}
+		// 算法在预发布后再次变更，作废尚未生效的密钥
+		if key == pending && key.Algorithm != JWT.Algorithm {
+			pending = nil
+			changed = true
+			continue
+		}
+		kept = append(kept, key)
+	}
+
+	switch {
+	case current == nil && pending != nil:
+		// 没有可以签名的密钥，预发布的密钥立即生效
+		pending.ActivatesAt = &now
+		current, pending = pending, nil
+		changed = true
+	case current == nil:
+		key, err := generateJWTKey(JWT.Algorithm, now)
+		if err != nil {
+			logger.Errorf("生成JWT密钥失败: %v", err)
+			return false
+		}
+		kept = append(kept, key)
+		current = key
+		changed = true
+	case pending == nil:
+		activatesAt := now.Add(JWKSCacheMaxAge)
+		prepublish := current.Algorithm != JWT.Algorithm
+		if JWT.RotationDays > 0 {
+			rotateAt := current.activeFrom().Add(time.Duration(JWT.RotationDays) * 24 * time.Hour)
+			if !now.Before(rotateAt.Add(-JWKSCacheMaxAge)) {
+				prepublish = true
+				if rotateAt.After(activatesAt) {
+					activatesAt = rotateAt
+				}
+			}
+		}
+		if prepublish {
+			key, err := generateJWTKey(JWT.Algorithm, now)
+			if err != nil {
+				logger.Errorf("生成JWT密钥失败: %v", err)
+				return false
+			}
+			key.ActivatesAt = &activatesAt
+			kept = append(kept, key)
+			changed = true
+		}
+	}
+
+	// 当前密钥生效后，之前的密钥只用于验证
+	for _, key := range kept {
+		if key == current {
+			break
+		}
+		if key.RetiredAt == nil {
+			retiredAt := current.activeFrom()
+			key.RetiredAt = &retiredAt
+			changed = true
+		}
+	}
+
+	if changed {
+		jwtKeys = kept
+		if err := saveJWTKeys(jwtKeys); err != nil {
+			logger.Errorf("保存JWT密钥失败: %v", err)
+		}
+	}
+	return changed
+}
+
+// generateJWTKey 按算法生成新的签名密钥
+func generateJWTKey(algorithm string, now time.Time) (*JWTKey, error) {
+	key := &JWTKey{ID: uuid.New().String(), Algorithm: algorithm, CreatedAt: now}
+
+	switch algorithm {
+	case JWTAlgorithmRS256:
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		key.signingKey, key.verifyKey = private, &private.PublicKey
+	case JWTAlgorithmEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.signingKey, key.verifyKey = private, public
+	default:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		key.signingKey, key.verifyKey = secret, secret
+	}
+	return key, nil
+}
+
+// loadJWTKeys 读取密钥集文件，文件不存在时返回 nil
+func loadJWTKeys() ([]*JWTKey, error) {
+	content, err := os.ReadFile(jwtKeySetFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []jwtKeyRecord
+	if err := json.Unmarshal(content, &records); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*JWTKey, 0, len(records))
+	for _, record := range records {
+		key := &JWTKey{
+			ID:          record.ID,
+			Algorithm:   record.Algorithm,
+			CreatedAt:   record.CreatedAt,
+			ActivatesAt: record.ActivatesAt,
+			RetiredAt:   record.RetiredAt,
+		}
+		if err := decodeJWTKeyMaterial(key, record.Key); err != nil {
+			return nil, fmt.Errorf("解析密钥 %s 失败: %v", record.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// saveJWTKeys 写入密钥集文件
+func saveJWTKeys(keys []*JWTKey) error {
+	records := make([]jwtKeyRecord, 0, len(keys))
+	for _, key := range keys {
+		material, err := encodeJWTKeyMaterial(key)
+		if err != nil {
+			return err
+		}
+		records = append(records, jwtKeyRecord{
+			ID:          key.ID,
+			Algorithm:   key.Algorithm,
+			Key:         material,
+			CreatedAt:   key.CreatedAt,
+			ActivatesAt: key.ActivatesAt,
+			RetiredAt:   key.RetiredAt,
+		})
+	}
+
+	content, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(jwtKeySetFile, content, 0600)
+}
+
+// migrateLegacyJWTKey 将旧版 jwtKey 文件中的密钥导入为已退役的 legacy 密钥，宽限期内仍可验证旧令牌
+func migrateLegacyJWTKey() ([]*JWTKey, error) {
+	content, err := os.ReadFile(legacyJWTKeyFile)
+	if os.IsNotExist(err) || (err == nil && len(content) == 0) {
+		return []*JWTKey{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	logger.Info("检测到旧版JWT密钥文件，已导入密钥集")
+	return []*JWTKey{{
+		ID:         legacyJWTKeyID,
+		Algorithm:  JWTAlgorithmHS256,
+		CreatedAt:  now,
+		RetiredAt:  &now,
+		signingKey: content,
+		verifyKey:  content,
+	}}, nil
+}
+
+// encodeJWTKeyMaterial 将密钥编码为可存储的字符串
+func encodeJWTKeyMaterial(key *JWTKey) (string, error) {
+	if secret, ok := key.signingKey.([]byte); ok {
+		return base64.StdEncoding.EncodeToString(secret), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key.signingKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// decodeJWTKeyMaterial 解析存储的密钥
+func decodeJWTKeyMaterial(key *JWTKey, material string) error {
+	if key.Algorithm == JWTAlgorithmHS256 {
+		secret, err := base64.StdEncoding.DecodeString(material)
+		if err != nil {
+			return err
+		}
+		key.signingKey, key.verifyKey = secret, secret
+		return nil
+	}
+
+	block, _ := pem.Decode([]byte(material))
+	if block == nil {
+		return errors.New("无效的PEM数据")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		key.signingKey, key.verifyKey = private, &private.PublicKey
+	case ed25519.PrivateKey:
+		key.signingKey, key.verifyKey = private, private.Public()
+	default:
+		return errors.New("不支持的私钥类型")
+	}
+	return nil
+}
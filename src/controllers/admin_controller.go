@@ -9,20 +9,6 @@ import (
 
 // 添加管理员
 func AddAdmin(c *gin.Context) {
-	// 验证当前用户是否为超级管理员
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isSuperAdmin, _ := services.IsSuperAdmin(currentUserID)
-	if !isSuperAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	// 获取请求参数
 	var req struct {
 		UserID uint   `json:"user_id" binding:"required"`
@@ -44,20 +30,6 @@ func AddAdmin(c *gin.Context) {
 
 // 移除管理员
 func RemoveAdmin(c *gin.Context) {
-	// 验证当前用户是否为超级管理员
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isSuperAdmin, _ := services.IsSuperAdmin(currentUserID)
-	if !isSuperAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	// 获取请求参数
 	var req struct {
 		UserID uint `json:"user_id" binding:"required"`
@@ -78,20 +50,6 @@ func RemoveAdmin(c *gin.Context) {
 
 // 获取管理员列表
 func GetAdminList(c *gin.Context) {
-	// 验证当前用户是否为管理员
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(currentUserID)
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	// 获取管理员列表
 	admins, err := services.GetAllAdmins()
 	if err != nil {
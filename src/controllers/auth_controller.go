@@ -2,7 +2,10 @@ package controllers
 
 import (
 	"OptiOJ/src/config"
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/services"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -41,41 +44,32 @@ func AuthController() gin.HandlerFunc {
 	}
 }
 
-// RefreshToken 刷新访问令牌
+// RefreshToken 刷新访问令牌，同时轮换刷新令牌，旧的刷新令牌立即失效
 func RefreshToken(c *gin.Context) {
-	refreshToken := c.GetHeader("Authorization")
+	refreshToken := middleware.ExtractToken(c)
 	if refreshToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "未提供刷新令牌"})
 		return
 	}
 
-	userID, err := services.ValidateRefreshToken(refreshToken)
+	accessToken, newRefreshToken, err := services.RotateRefreshToken(c, refreshToken)
 	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的刷新令牌"})
 		return
 	}
 
-	// 生成新的访问令牌
-	newAccessToken, err := services.GenerateToken(userID, 2*time.Hour)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成访问令牌失败"})
-		return
-	}
-
-	// 存储新的访问令牌
-	accessSessionKey := "access_token:" + newAccessToken
-	if err := config.RedisClient.Set(c, accessSessionKey, userID, 2*time.Hour).Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "存储访问令牌失败"})
-		return
-	}
-
-	// 更新会话信息
-	if err := services.UpdateSessionLastRefresh(c, refreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新会话信息失败"})
-		return
-	}
-
 	c.JSON(http.StatusOK, gin.H{
-		"access_token": newAccessToken,
+		"access_token":  accessToken,
+		"refresh_token": newRefreshToken,
 	})
 }
+
+// GetJWKS 获取用于验证令牌签名的公钥集合
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(config.JWKSCacheMaxAge.Seconds())))
+	c.JSON(http.StatusOK, config.PublicJWKS())
+}
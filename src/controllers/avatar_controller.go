@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/services"
 	"net/http"
 	"os"
@@ -10,15 +11,7 @@ import (
 )
 
 func UploadAvatar(c *gin.Context) {
-	// 从请求头获取访问令牌
-	accessToken := c.GetHeader("Authorization")
-
-	// 验证访问令牌并获取用户ID
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	// 获取上传的文件
 	file, err := c.FormFile("avatar")
@@ -62,15 +55,7 @@ func GetAvatar(c *gin.Context) {
 }
 
 func RemoveAvatar(c *gin.Context) {
-	// 从请求头获取访问令牌
-	accessToken := c.GetHeader("Authorization")
-
-	// 验证访问令牌并获取用户ID
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	// 删除头像
 	if err := services.RemoveAvatar(userID); err != nil {
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 	"net/http"
@@ -11,13 +12,7 @@ import (
 
 // SubmitCode 提交代码
 func SubmitCode(c *gin.Context) {
-	// 获取当前用户ID
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	var req models.SubmissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,13 +35,7 @@ func SubmitCode(c *gin.Context) {
 
 // GetSubmissionList 获取提交记录列表
 func GetSubmissionList(c *gin.Context) {
-	// 获取当前用户ID
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	var req models.SubmissionListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -54,10 +43,10 @@ func GetSubmissionList(c *gin.Context) {
 		return
 	}
 
-	// 检查是否为管理员
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		// 非管理员只能查看自己的提交记录
+	// 检查是否有权查看全部提交记录
+	canViewAll := middleware.HasPermission(c, models.SitePermSubmissionView)
+	if !canViewAll {
+		// 无权限时只能查看自己的提交记录
 		userID := uint64(currentUserID) // 转换为 uint64
 		req.UserID = &userID
 	}
@@ -76,13 +65,7 @@ func GetSubmissionList(c *gin.Context) {
 
 // GetSubmissionDetail 获取提交记录详情
 func GetSubmissionDetail(c *gin.Context) {
-	// 获取当前用户ID
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -96,9 +79,10 @@ func GetSubmissionDetail(c *gin.Context) {
 		return
 	}
 
-	// 检查访问权限
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin && detail.UserID != uint64(currentUserID) {
+	// 检查访问权限；个人访问令牌只能查看自己的提交，不继承团队内查看全部提交的权限
+	canViewAll := middleware.HasPermission(c, models.SitePermSubmissionView)
+	canViewTeam := !middleware.IsPersonalToken(c) && services.CanViewAssignmentSubmission(&detail.Submission, uint64(currentUserID))
+	if !canViewAll && detail.UserID != uint64(currentUserID) && !canViewTeam {
 		c.JSON(http.StatusForbidden, gin.H{"error": "无权访问该提交记录"})
 		return
 	}
@@ -111,13 +95,6 @@ func GetSubmissionDetail(c *gin.Context) {
 
 // Debug 在线调试代码
 func Debug(c *gin.Context) {
-	// 获取当前用户ID
-	accessToken := c.GetHeader("Authorization")
-	_, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
 
 	var req models.DebugRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
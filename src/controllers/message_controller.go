@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 	"net/http"
@@ -11,13 +12,7 @@ import (
 
 // GetMessageList 获取站内信列表
 func GetMessageList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.MessageListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -39,13 +34,7 @@ func GetMessageList(c *gin.Context) {
 
 // MarkMessageAsRead 标记消息为已读
 func MarkMessageAsRead(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	messageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -66,13 +55,7 @@ func MarkMessageAsRead(c *gin.Context) {
 
 // MarkAllMessagesAsRead 标记所有消息为已读
 func MarkAllMessagesAsRead(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	if err := services.MarkAllMessagesAsRead(uint64(userID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -87,13 +70,7 @@ func MarkAllMessagesAsRead(c *gin.Context) {
 
 // DeleteMessage 删除消息
 func DeleteMessage(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	messageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -114,13 +91,7 @@ func DeleteMessage(c *gin.Context) {
 
 // GetUnreadMessageCount 获取未读消息数量
 func GetUnreadMessageCount(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	response, err := services.GetUnreadMessageCount(uint64(userID))
 	if err != nil {
@@ -136,13 +107,7 @@ func GetUnreadMessageCount(c *gin.Context) {
 
 // CreateTeamApplication 创建团队申请
 func CreateTeamApplication(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.TeamApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -163,13 +128,7 @@ func CreateTeamApplication(c *gin.Context) {
 
 // GetTeamApplicationList 获取团队申请列表
 func GetTeamApplicationList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.TeamApplicationListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -191,13 +150,7 @@ func GetTeamApplicationList(c *gin.Context) {
 
 // HandleTeamApplication 处理团队申请
 func HandleTeamApplication(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.TeamApplicationHandleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -218,13 +171,7 @@ func HandleTeamApplication(c *gin.Context) {
 
 // BatchMarkMessagesAsRead 批量标记消息为已读
 func BatchMarkMessagesAsRead(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.BatchReadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+)
+
+// GetOIDCProvider 获取单点登录配置信息
+func GetOIDCProvider(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": services.GetOIDCProviderInfo(),
+	})
+}
+
+// GetOIDCAuthorizationURL 获取跳转到身份提供方的授权地址
+func GetOIDCAuthorizationURL(c *gin.Context) {
+	authURL, err := services.GetOIDCAuthorizationURL()
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{"authorization_url": authURL},
+	})
+}
+
+// OIDCLogin 使用身份提供方返回的授权码登录，之后的流程与密码登录相同
+func OIDCLogin(c *gin.Context) {
+	var req models.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	user, err := services.OIDCLogin(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	finishLogin(c, user)
+}
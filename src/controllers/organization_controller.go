@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOrganization 创建组织
+func CreateOrganization(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	org, err := services.CreateOrganization(&req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": org,
+	})
+}
+
+// UpdateOrganization 更新组织信息
+func UpdateOrganization(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	var req models.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.UpdateOrganization(orgID, &req, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "更新组织成功",
+	})
+}
+
+// DeleteOrganization 删除组织
+func DeleteOrganization(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	if err := services.DeleteOrganization(orgID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除组织成功",
+	})
+}
+
+// GetOrganizationTree 获取组织树
+func GetOrganizationTree(c *gin.Context) {
+	var rootID uint64
+	if root := c.Query("root_id"); root != "" {
+		id, err := strconv.ParseUint(root, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+			return
+		}
+		rootID = id
+	}
+
+	tree, err := services.GetOrganizationTree(rootID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": tree,
+	})
+}
+
+// GetOrganizationAdmins 获取组织管理员列表
+func GetOrganizationAdmins(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	admins, err := services.GetOrganizationAdmins(orgID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": admins,
+	})
+}
+
+// AddOrganizationAdmin 添加组织管理员
+func AddOrganizationAdmin(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	var req models.OrganizationUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.AddOrganizationAdmin(orgID, req.UserID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "添加组织管理员成功",
+	})
+}
+
+// RemoveOrganizationAdmin 移除组织管理员
+func RemoveOrganizationAdmin(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	targetUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	if err := services.RemoveOrganizationAdmin(orgID, targetUserID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "移除组织管理员成功",
+	})
+}
+
+// GetOrganizationProblems 获取组织题库
+func GetOrganizationProblems(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	problems, err := services.GetOrganizationProblems(orgID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": problems,
+	})
+}
+
+// AddOrganizationProblem 将题目加入组织题库
+func AddOrganizationProblem(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	var req models.OrganizationProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.AddOrganizationProblem(orgID, req.ProblemID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "添加题目成功",
+	})
+}
+
+// RemoveOrganizationProblem 将题目移出组织题库
+func RemoveOrganizationProblem(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	problemID, err := strconv.ParseUint(c.Param("problem_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
+		return
+	}
+
+	if err := services.RemoveOrganizationProblem(orgID, problemID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "移除题目成功",
+	})
+}
+
+// GetSchoolMembers 获取关联到学校组织的用户
+func GetSchoolMembers(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	members, err := services.GetSchoolMembers(orgID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": members,
+	})
+}
+
+// VerifySchoolMember 认证或取消认证用户的学校关联
+func VerifySchoolMember(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的组织ID"})
+		return
+	}
+
+	targetUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var req models.VerifySchoolMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.VerifySchoolMember(orgID, targetUserID, *req.Verified, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "操作成功",
+	})
+}
+
+// SetTeamOrganization 设置团队所属组织
+func SetTeamOrganization(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	var req models.SetTeamOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.SetTeamOrganization(teamID, &req, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "设置团队所属组织成功",
+	})
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+)
+
+// CreatePersonalAccessToken 创建个人访问令牌，完整令牌只在创建时返回一次
+func CreatePersonalAccessToken(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	resp, err := services.CreatePersonalAccessToken(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "令牌创建成功，请妥善保存，关闭后将无法再次查看",
+		"data":    resp,
+	})
+}
+
+// GetPersonalAccessTokens 获取当前用户的个人访问令牌列表
+func GetPersonalAccessTokens(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	tokens, err := services.GetPersonalAccessTokens(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取令牌列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": tokens,
+	})
+}
+
+// RevokePersonalAccessToken 吊销个人访问令牌
+func RevokePersonalAccessToken(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的令牌ID"})
+		return
+	}
+
+	if err := services.RevokePersonalAccessToken(userID, tokenID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "令牌已吊销",
+	})
+}
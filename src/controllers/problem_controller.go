@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 	"net/http"
@@ -13,19 +14,7 @@ import (
 
 // CreateProblem 创建题目
 func CreateProblem(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	var req models.CreateProblemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -48,19 +37,7 @@ func CreateProblem(c *gin.Context) {
 
 // AdminGetProblemList 管理员获取题目列表
 func AdminGetProblemList(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	var req models.ProblemListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -83,19 +60,7 @@ func AdminGetProblemList(c *gin.Context) {
 
 // AdminGetProblemDetail 管理员获取题目详情
 func AdminGetProblemDetail(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -103,7 +68,7 @@ func AdminGetProblemDetail(c *gin.Context) {
 		return
 	}
 
-	problem, err := services.GetProblemDetail(problemID, uint64(currentUserID))
+	problem, err := services.GetProblemDetail(problemID, uint64(currentUserID), middleware.HasPermission(c, models.SitePermProblemRead))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -140,20 +105,6 @@ func AdminGetProblemDetail(c *gin.Context) {
 
 // AdminUpdateProblem 管理员更新题目
 func AdminUpdateProblem(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
@@ -179,20 +130,6 @@ func AdminUpdateProblem(c *gin.Context) {
 
 // DeleteProblem 删除题目
 func DeleteProblem(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
@@ -212,15 +149,7 @@ func DeleteProblem(c *gin.Context) {
 
 // GetProblemDetail 获取题目详情
 func GetProblemDetail(c *gin.Context) {
-	// 获取当前用户ID（可选）
-	var currentUserID uint
-	accessToken := c.GetHeader("Authorization")
-	if accessToken != "" {
-		userID, err := services.ValidateAccessToken(accessToken)
-		if err == nil {
-			currentUserID = userID
-		}
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -228,7 +157,7 @@ func GetProblemDetail(c *gin.Context) {
 		return
 	}
 
-	problem, err := services.GetProblemDetail(problemID, uint64(currentUserID))
+	problem, err := services.GetProblemDetail(problemID, uint64(currentUserID), middleware.HasPermission(c, models.SitePermProblemRead))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -242,15 +171,7 @@ func GetProblemDetail(c *gin.Context) {
 
 // GetProblemList 获取题目列表
 func GetProblemList(c *gin.Context) {
-	// 获取当前用户ID（可选）
-	var currentUserID uint
-	accessToken := c.GetHeader("Authorization")
-	if accessToken != "" {
-		userID, err := services.ValidateAccessToken(accessToken)
-		if err == nil {
-			currentUserID = userID
-		}
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	var req models.ProblemListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -278,20 +199,6 @@ func GetProblemList(c *gin.Context) {
 
 // UploadTestCase 上传测试用例
 func UploadTestCase(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	var req models.TestCaseUploadRequest
 	if err := c.ShouldBind(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
@@ -357,20 +264,6 @@ func UploadTestCase(c *gin.Context) {
 
 // DeleteTestCase 删除测试用例
 func DeleteTestCase(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	testCaseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的测试用例ID"})
@@ -390,20 +283,6 @@ func DeleteTestCase(c *gin.Context) {
 
 // GetTestCases 获取题目的测试用例列表
 func GetTestCases(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	problemID, err := strconv.ParseUint(c.Param("problem_id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
@@ -424,14 +303,6 @@ func GetTestCases(c *gin.Context) {
 
 // GetTestCaseContent 获取测试用例内容
 func GetTestCaseContent(c *gin.Context) {
-	// 获取当前用户ID
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
 	// 获取测试用例ID
 	testCaseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -439,13 +310,6 @@ func GetTestCaseContent(c *gin.Context) {
 		return
 	}
 
-	// 检查管理员权限
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	// 获取测试用例内容
 	content, err := services.GetTestCaseContent(testCaseID)
 	if err != nil {
@@ -461,20 +325,6 @@ func GetTestCaseContent(c *gin.Context) {
 
 // CreateTag 创建标签
 func CreateTag(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	var req models.CreateTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
@@ -496,20 +346,6 @@ func CreateTag(c *gin.Context) {
 
 // UpdateTag 更新标签
 func UpdateTag(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
@@ -535,20 +371,6 @@ func UpdateTag(c *gin.Context) {
 
 // DeleteTag 删除标签
 func DeleteTag(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标签ID"})
@@ -588,20 +410,6 @@ func GetTagList(c *gin.Context) {
 
 // SwitchDifficultySystem 切换难度等级系统
 func SwitchDifficultySystem(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	var req models.SwitchDifficultySystemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
@@ -635,20 +443,6 @@ func GetDifficultySystem(c *gin.Context) {
 
 // CreateTagCategory 创建标签分类
 func CreateTagCategory(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	var req models.CreateTagCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
@@ -670,20 +464,6 @@ func CreateTagCategory(c *gin.Context) {
 
 // UpdateTagCategory 更新标签分类
 func UpdateTagCategory(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分类ID"})
@@ -709,20 +489,6 @@ func UpdateTagCategory(c *gin.Context) {
 
 // DeleteTagCategory 删除标签分类
 func DeleteTagCategory(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(uint(currentUserID))
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分类ID"})
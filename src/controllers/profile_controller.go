@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 	"net/http"
@@ -10,15 +11,7 @@ import (
 )
 
 func UpdateProfile(c *gin.Context) {
-	// 从请求头获取访问令牌
-	accessToken := c.GetHeader("Authorization")
-
-	// 验证访问令牌并获取用户ID
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1,21 +1,18 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/services"
 )
 
 // GetActiveSessions 获取当前用户的所有活跃会话
 func GetActiveSessions(c *gin.Context) {
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	sessions, err := services.GetActiveSessions(userID)
 	if err != nil {
@@ -30,12 +27,7 @@ func GetActiveSessions(c *gin.Context) {
 
 // RevokeSession 吊销指定会话
 func RevokeSession(c *gin.Context) {
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	sessionID := c.Param("session_id")
 	if sessionID == "" {
@@ -43,27 +35,11 @@ func RevokeSession(c *gin.Context) {
 		return
 	}
 
-	// 验证会话是否属于当前用户
-	sessions, err := services.GetActiveSessions(userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取会话信息失败"})
-		return
-	}
-
-	var found bool
-	for _, session := range sessions {
-		if session.SessionID == sessionID {
-			found = true
-			break
+	if err := services.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "无权操作此会话"})
+			return
 		}
-	}
-
-	if !found {
-		c.JSON(http.StatusForbidden, gin.H{"error": "无权操作此会话"})
-		return
-	}
-
-	if err := services.RevokeSession(sessionID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "吊销会话失败"})
 		return
 	}
@@ -75,7 +51,7 @@ func RevokeSession(c *gin.Context) {
 
 // Logout 退出当前设备
 func Logout(c *gin.Context) {
-	refreshToken := c.GetHeader("Authorization")
+	refreshToken := middleware.ExtractToken(c)
 	if refreshToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "未提供刷新令牌"})
 		return
@@ -99,12 +75,7 @@ func Logout(c *gin.Context) {
 
 // LogoutAllDevices 退出所有设备
 func LogoutAllDevices(c *gin.Context) {
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	if err := services.LogoutAllDevices(userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "退出所有设备失败"})
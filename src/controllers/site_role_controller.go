@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSiteRoles 获取网站角色列表
+func GetSiteRoles(c *gin.Context) {
+	roles, err := services.GetSiteRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"roles":       roles,
+			"permissions": models.AllSitePermissions,
+		},
+	})
+}
+
+// SaveSiteRole 创建自定义网站角色或修改角色权限
+func SaveSiteRole(c *gin.Context) {
+	var req models.SaveSiteRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.SaveSiteRole(c.Param("role"), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "保存角色成功",
+	})
+}
+
+// DeleteSiteRole 删除自定义网站角色或恢复内置角色的默认权限
+func DeleteSiteRole(c *gin.Context) {
+	if err := services.DeleteSiteRole(c.Param("role")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除角色成功",
+	})
+}
+
+// GetUserSiteRoles 获取用户的网站角色
+func GetUserSiteRoles(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	roles, err := services.GetUserSiteRoles(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": roles,
+	})
+}
+
+// SetUserSiteRoles 设置用户的网站角色
+func SetUserSiteRoles(c *gin.Context) {
+	currentUserID := middleware.CurrentUserID(c)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var req models.SetUserSiteRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.SetUserSiteRoles(userID, &req, uint64(currentUserID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "设置用户角色成功",
+	})
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTeamEvents 获取团队动态
+func GetTeamEvents(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	var req models.TeamEventListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	response, err := services.GetTeamEvents(teamID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": response,
+	})
+}
+
+// GetTeamStats 获取团队统计面板
+func GetTeamStats(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	var req models.TeamStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	stats, err := services.GetTeamStats(teamID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": stats,
+	})
+}
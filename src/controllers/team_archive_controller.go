@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveTeam 归档团队
+func ArchiveTeam(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	if err := services.ArchiveTeam(teamID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "团队已归档",
+	})
+}
+
+// RestoreTeam 恢复已归档的团队
+func RestoreTeam(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	if err := services.RestoreTeam(teamID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "团队已恢复",
+	})
+}
+
+// TransferTeamOwnership 发起团队所有权转让
+func TransferTeamOwnership(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	var req models.TransferTeamOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	transfer, err := services.TransferTeamOwnership(teamID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "转让请求已发送，等待对方确认",
+		"data":    transfer,
+	})
+}
+
+// CancelOwnershipTransfer 取消待确认的所有权转让
+func CancelOwnershipTransfer(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	if err := services.CancelOwnershipTransfer(teamID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已取消转让",
+	})
+}
+
+// GetPendingOwnershipTransfers 获取等待当前用户确认的所有权转让
+func GetPendingOwnershipTransfers(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	transfers, err := services.GetPendingOwnershipTransfers(uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": transfers,
+	})
+}
+
+// RespondOwnershipTransfer 确认或拒绝所有权转让
+func RespondOwnershipTransfer(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	transferID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的转让ID"})
+		return
+	}
+
+	var req models.RespondOwnershipTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.RespondOwnershipTransfer(transferID, *req.Accept, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "已拒绝转让"
+	if *req.Accept {
+		message = "已接受转让"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+	})
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CloneAssignment 复制作业
+func CloneAssignment(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的作业ID"})
+		return
+	}
+
+	var req models.CloneAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	newAssignmentID, err := services.CloneAssignment(assignmentID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "复制作业成功",
+		"data": gin.H{
+			"assignment_id": newAssignmentID,
+		},
+	})
+}
+
+// SaveAssignmentTemplate 将作业保存为模板
+func SaveAssignmentTemplate(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的作业ID"})
+		return
+	}
+
+	var req models.SaveAssignmentTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	templateID, err := services.SaveAssignmentTemplate(assignmentID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "保存模板成功",
+		"data": gin.H{
+			"template_id": templateID,
+		},
+	})
+}
+
+// GetAssignmentTemplates 获取团队的作业模板列表
+func GetAssignmentTemplates(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Query("team_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	templates, err := services.GetAssignmentTemplates(teamID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": templates,
+	})
+}
+
+// DeleteAssignmentTemplate 删除作业模板
+func DeleteAssignmentTemplate(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的模板ID"})
+		return
+	}
+
+	if err := services.DeleteAssignmentTemplate(templateID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除模板成功",
+	})
+}
+
+// CreateAssignmentFromTemplate 从模板创建作业
+func CreateAssignmentFromTemplate(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.CreateAssignmentFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	assignmentID, err := services.CreateAssignmentFromTemplate(&req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "创建作业成功",
+		"data": gin.H{
+			"assignment_id": assignmentID,
+		},
+	})
+}
+
+// PublishAssignment 立即发布作业
+func PublishAssignment(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的作业ID"})
+		return
+	}
+
+	if err := services.PublishAssignment(assignmentID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "发布作业成功",
+	})
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
+	"io"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -12,13 +14,7 @@ import (
 
 // CreateTeam 创建团队
 func CreateTeam(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.CreateTeamRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -43,13 +39,7 @@ func CreateTeam(c *gin.Context) {
 
 // UpdateTeam 更新团队信息
 func UpdateTeam(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -76,13 +66,7 @@ func UpdateTeam(c *gin.Context) {
 
 // DeleteTeam 删除团队
 func DeleteTeam(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -97,21 +81,13 @@ func DeleteTeam(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "删除团队成功",
+		"message": "团队已归档",
 	})
 }
 
 // GetTeamDetail 获取团队详情
 func GetTeamDetail(c *gin.Context) {
-	// 获取用户身份（可选）
-	var userID uint
-	accessToken := c.GetHeader("Authorization")
-	if accessToken != "" {
-		id, err := services.ValidateAccessToken(accessToken)
-		if err == nil {
-			userID = id
-		}
-	}
+	userID := middleware.CurrentUserID(c)
 
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -133,15 +109,7 @@ func GetTeamDetail(c *gin.Context) {
 
 // GetTeamList 获取团队列表
 func GetTeamList(c *gin.Context) {
-	// 获取用户身份（可选）
-	var userID uint
-	accessToken := c.GetHeader("Authorization")
-	if accessToken != "" {
-		id, err := services.ValidateAccessToken(accessToken)
-		if err == nil {
-			userID = id
-		}
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.TeamListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -163,13 +131,7 @@ func GetTeamList(c *gin.Context) {
 
 // CreateTeamInvitation 创建团队邀请
 func CreateTeamInvitation(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -177,7 +139,14 @@ func CreateTeamInvitation(c *gin.Context) {
 		return
 	}
 
-	invitation, err := services.CreateTeamInvitation(teamID, uint64(userID))
+	// 请求体可以省略，此时使用默认设置
+	var req models.CreateTeamInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	invitation, err := services.CreateTeamInvitation(teamID, &req, uint64(userID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -191,13 +160,7 @@ func CreateTeamInvitation(c *gin.Context) {
 
 // JoinTeam 加入团队
 func JoinTeam(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req struct {
 		Code string `json:"code" binding:"required"`
@@ -218,16 +181,65 @@ func JoinTeam(c *gin.Context) {
 	})
 }
 
-// CreateAssignment 创建团队作业
-func CreateAssignment(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
+// GetTeamInvitations 获取团队邀请列表
+func GetTeamInvitations(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	var req models.TeamInvitationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	invitations, err := services.GetTeamInvitations(teamID, &req, uint64(userID))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": invitations,
+	})
+}
+
+// RevokeTeamInvitation 撤销团队邀请
+func RevokeTeamInvitation(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	invitationID, err := strconv.ParseUint(c.Param("invitation_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的邀请ID"})
+		return
+	}
+
+	if err := services.RevokeTeamInvitation(teamID, invitationID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "撤销邀请成功",
+	})
+}
+
+// CreateAssignment 创建团队作业
+func CreateAssignment(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
 	var req models.CreateAssignmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
@@ -251,13 +263,7 @@ func CreateAssignment(c *gin.Context) {
 
 // UpdateAssignment 更新团队作业
 func UpdateAssignment(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -284,13 +290,7 @@ func UpdateAssignment(c *gin.Context) {
 
 // CreateProblemList 创建团队题单
 func CreateProblemList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.CreateProblemListRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -315,13 +315,7 @@ func CreateProblemList(c *gin.Context) {
 
 // UpdateProblemList 更新团队题单
 func UpdateProblemList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -348,15 +342,9 @@ func UpdateProblemList(c *gin.Context) {
 
 // UpdateTeamMemberRole 更新团队成员角色
 func UpdateTeamMemberRole(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
-	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 64)
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
 		return
@@ -384,15 +372,9 @@ func UpdateTeamMemberRole(c *gin.Context) {
 
 // RemoveTeamMember 移除团队成员
 func RemoveTeamMember(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
-	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 64)
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
 		return
@@ -417,13 +399,7 @@ func RemoveTeamMember(c *gin.Context) {
 
 // GetAssignmentDetail 获取作业详情
 func GetAssignmentDetail(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -445,13 +421,7 @@ func GetAssignmentDetail(c *gin.Context) {
 
 // GetAssignmentList 获取作业列表
 func GetAssignmentList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	teamID, err := strconv.ParseUint(c.Query("team_id"), 10, 64)
 	if err != nil {
@@ -473,15 +443,7 @@ func GetAssignmentList(c *gin.Context) {
 
 // GetProblemListDetail 获取题单详情
 func GetProblemListDetail(c *gin.Context) {
-	// 验证用户身份（可选）
-	var userID uint
-	accessToken := c.GetHeader("Authorization")
-	if accessToken != "" {
-		id, err := services.ValidateAccessToken(accessToken)
-		if err == nil {
-			userID = id
-		}
-	}
+	userID := middleware.CurrentUserID(c)
 
 	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -501,17 +463,31 @@ func GetProblemListDetail(c *gin.Context) {
 	})
 }
 
+// GetProblemListProgress 获取题单成员进度和排行榜
+func GetProblemListProgress(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题单ID"})
+		return
+	}
+
+	progress, err := services.GetProblemListProgress(listID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": progress,
+	})
+}
+
 // GetProblemListList 获取题单列表
 func GetProblemListList(c *gin.Context) {
-	// 验证用户身份（可选）
-	var userID uint
-	accessToken := c.GetHeader("Authorization")
-	if accessToken != "" {
-		id, err := services.ValidateAccessToken(accessToken)
-		if err == nil {
-			userID = id
-		}
-	}
+	userID := middleware.CurrentUserID(c)
 
 	teamID, err := strconv.ParseUint(c.Query("team_id"), 10, 64)
 	if err != nil {
@@ -533,13 +509,7 @@ func GetProblemListList(c *gin.Context) {
 
 // UploadTeamAvatar 上传团队头像
 func UploadTeamAvatar(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	// 获取团队ID
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -549,13 +519,8 @@ func UploadTeamAvatar(c *gin.Context) {
 	}
 
 	// 检查用户权限
-	role, err := services.GetTeamUserRole(teamID, uint64(userID))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if role != "owner" && role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+	if _, err := services.AuthorizeTeam(teamID, uint64(userID), models.TeamPermManageTeam); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -607,13 +572,7 @@ func GetTeamAvatar(c *gin.Context) {
 
 // RemoveTeamAvatar 删除团队头像
 func RemoveTeamAvatar(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	// 获取团队ID
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -623,13 +582,8 @@ func RemoveTeamAvatar(c *gin.Context) {
 	}
 
 	// 检查用户权限
-	role, err := services.GetTeamUserRole(teamID, uint64(userID))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if role != "owner" && role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+	if _, err := services.AuthorizeTeam(teamID, uint64(userID), models.TeamPermManageTeam); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -647,13 +601,7 @@ func RemoveTeamAvatar(c *gin.Context) {
 
 // GetTeamMemberList 获取团队成员列表
 func GetTeamMemberList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	// 获取团队ID
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -684,13 +632,7 @@ func GetTeamMemberList(c *gin.Context) {
 
 // UpdateTeamNickname 更新团队内名称
 func UpdateTeamNickname(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	// 获取团队ID
 	teamID, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -720,13 +662,7 @@ func UpdateTeamNickname(c *gin.Context) {
 
 // GetAvailableProblemList 获取可用题目列表
 func GetAvailableProblemList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.AvailableProblemListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -748,13 +684,7 @@ func GetAvailableProblemList(c *gin.Context) {
 
 // GetAssignmentProblems 获取作业题目列表
 func GetAssignmentProblems(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.GetAssignmentProblemsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -776,13 +706,7 @@ func GetAssignmentProblems(c *gin.Context) {
 
 // GetAssignmentProblemDetail 获取作业题目详情
 func GetAssignmentProblemDetail(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.GetAssignmentProblemDetailRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -804,13 +728,7 @@ func GetAssignmentProblemDetail(c *gin.Context) {
 
 // SubmitAssignmentCode 提交作业代码
 func SubmitAssignmentCode(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.SubmitAssignmentCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -834,13 +752,7 @@ func SubmitAssignmentCode(c *gin.Context) {
 
 // GetAssignmentSubmissions 获取作业提交记录
 func GetAssignmentSubmissions(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.GetAssignmentSubmissionsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
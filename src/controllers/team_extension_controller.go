@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GrantAssignmentExtension 为成员延期作业截止时间
+func GrantAssignmentExtension(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的作业ID"})
+		return
+	}
+
+	var req models.GrantAssignmentExtensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.GrantAssignmentExtension(assignmentID, &req, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "延期成功",
+	})
+}
+
+// RevokeAssignmentExtension 撤销成员的作业延期
+func RevokeAssignmentExtension(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的作业ID"})
+		return
+	}
+
+	targetUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	if err := services.RevokeAssignmentExtension(assignmentID, targetUserID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "撤销延期成功",
+	})
+}
+
+// GetAssignmentExtensions 获取作业的延期列表
+func GetAssignmentExtensions(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的作业ID"})
+		return
+	}
+
+	extensions, err := services.GetAssignmentExtensions(assignmentID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": extensions,
+	})
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAssignmentGradebook 获取作业成绩表
+func GetAssignmentGradebook(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.GetAssignmentGradebookRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	response, err := services.GetAssignmentGradebook(&req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": response,
+	})
+}
+
+// ExportAssignmentGradebook 导出作业成绩表
+func ExportAssignmentGradebook(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.ExportAssignmentGradebookRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	gradebook, err := services.GetAssignmentGradebook(&models.GetAssignmentGradebookRequest{
+		AssignmentID: req.AssignmentID,
+		TeamID:       req.TeamID,
+	}, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	table := services.BuildGradebookTable(gradebook)
+
+	var content []byte
+	var contentType string
+	if req.Format == "xlsx" {
+		content, err = services.WriteXLSX("成绩表", table)
+		contentType = services.ContentTypeXLSX
+	} else {
+		req.Format = "csv"
+		content, err = services.WriteCSV(table)
+		contentType = services.ContentTypeCSV
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("assignment_%d_gradebook.%s", req.AssignmentID, req.Format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, contentType, content)
+}
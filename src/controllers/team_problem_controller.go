@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 	"net/http"
@@ -11,13 +12,7 @@ import (
 
 // CreateTeamProblem 创建团队私有题目
 func CreateTeamProblem(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.CreateTeamProblemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -42,13 +37,7 @@ func CreateTeamProblem(c *gin.Context) {
 
 // UpdateTeamProblem 更新团队私有题目
 func UpdateTeamProblem(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -75,13 +64,7 @@ func UpdateTeamProblem(c *gin.Context) {
 
 // DeleteTeamProblem 删除团队私有题目
 func DeleteTeamProblem(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -102,13 +85,7 @@ func DeleteTeamProblem(c *gin.Context) {
 
 // GetTeamProblemDetail 获取团队私有题目详情
 func GetTeamProblemDetail(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
@@ -130,13 +107,7 @@ func GetTeamProblemDetail(c *gin.Context) {
 
 // GetTeamProblemList 获取团队私有题目列表
 func GetTeamProblemList(c *gin.Context) {
-	// 验证用户身份
-	accessToken := c.GetHeader("Authorization")
-	userID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
+	userID := middleware.CurrentUserID(c)
 
 	var req models.TeamProblemListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareTeamProblem 将团队私有题目共享给其他团队
+func ShareTeamProblem(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
+		return
+	}
+
+	var req models.ShareTeamProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.ShareTeamProblem(problemID, &req, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "共享题目成功",
+	})
+}
+
+// UnshareTeamProblem 取消对某个团队的题目共享
+func UnshareTeamProblem(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	if err := services.UnshareTeamProblem(problemID, teamID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "取消共享成功",
+	})
+}
+
+// GetTeamProblemShares 获取题目的共享记录
+func GetTeamProblemShares(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
+		return
+	}
+
+	shares, err := services.GetTeamProblemShares(problemID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": shares,
+	})
+}
+
+// GetSharedTeamProblems 获取其他团队共享给本团队的题目
+func GetSharedTeamProblems(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	teamID, err := strconv.ParseUint(c.Query("team_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的团队ID"})
+		return
+	}
+
+	problems, err := services.GetSharedTeamProblems(teamID, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": problems,
+	})
+}
+
+// CopySharedTeamProblem 将共享的题目复制到本团队
+func CopySharedTeamProblem(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
+		return
+	}
+
+	var req models.CopyTeamProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	newID, err := services.CopySharedTeamProblem(problemID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "复制题目成功",
+		"data": gin.H{
+			"problem_id": newID,
+		},
+	})
+}
+
+// NominateTeamProblem 推荐团队私有题目进入公共题库
+func NominateTeamProblem(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	problemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的题目ID"})
+		return
+	}
+
+	// 推荐理由可选，允许空请求体
+	var req models.NominateTeamProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	promotion, err := services.NominateTeamProblem(problemID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "推荐成功，等待管理员审核",
+		"data":    promotion,
+	})
+}
+
+// GetProblemPromotions 获取题目推荐申请列表（管理员）
+func GetProblemPromotions(c *gin.Context) {
+	var req models.ProblemPromotionListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	promotions, err := services.GetProblemPromotions(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": promotions,
+	})
+}
+
+// ReviewProblemPromotion 审核题目推荐申请（管理员）
+func ReviewProblemPromotion(c *gin.Context) {
+	currentUserID := middleware.CurrentUserID(c)
+
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的申请ID"})
+		return
+	}
+
+	var req models.ReviewProblemPromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.ReviewProblemPromotion(promotionID, &req, uint64(currentUserID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "审核完成",
+	})
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddSubmissionComment 为作业提交添加代码行评论
+func AddSubmissionComment(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提交ID"})
+		return
+	}
+
+	var req models.AddSubmissionCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	comment, err := services.AddSubmissionComment(submissionID, &req, uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": comment,
+	})
+}
+
+// DeleteSubmissionComment 删除代码行评论
+func DeleteSubmissionComment(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提交ID"})
+		return
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	if err := services.DeleteSubmissionComment(submissionID, commentID, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除评论成功",
+	})
+}
+
+// GradeSubmission 对作业提交进行人工评分
+func GradeSubmission(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提交ID"})
+		return
+	}
+
+	var req models.GradeSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.GradeSubmission(submissionID, &req, uint64(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "批阅成功",
+	})
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+)
+
+// TwoFactorLogin 登录第二步：提交两步验证码或恢复码，验证通过后签发令牌
+func TwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	// 两步验证与密码登录共用账号的失败次数、等待时间和锁定
+	challengeUserID, err := services.GetLoginChallengeUser(req.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	attempt, err := services.CheckUserLoginAttempt(uint(challengeUserID), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查登录状态失败"})
+		return
+	}
+	if !allowLoginAttempt(c, attempt, req.CaptchaID) {
+		return
+	}
+
+	userID, recoveryCodes, err := services.CompleteTwoFactorLogin(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			respondLoginFailure(c, attempt, err)
+			return
+		}
+		services.RecordLogin(c, uint(challengeUserID), "failed", err.Error())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := services.GetUserByID(uint(userID), &user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户信息失败"})
+		return
+	}
+
+	// 挑战有效期内账号可能被封禁
+	banned, reason, _ := services.IsUserBanned(uint(user.ID))
+	if banned {
+		services.RecordLogin(c, uint(user.ID), "blocked", reason)
+		c.JSON(http.StatusForbidden, gin.H{"error": "账号已被封禁，原因：" + reason})
+		return
+	}
+
+	completeLogin(c, &user, recoveryCodes)
+}
+
+// SetupTwoFactorByChallenge 必须启用两步验证但尚未绑定的账号，在登录过程中生成密钥
+func SetupTwoFactorByChallenge(c *gin.Context) {
+	var req models.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	setup, err := services.SetupTwoFactorByChallenge(req.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": setup,
+	})
+}
+
+// GetTwoFactorStatus 获取当前用户的两步验证状态
+func GetTwoFactorStatus(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	status, err := services.GetTwoFactorStatus(uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取两步验证状态失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": status,
+	})
+}
+
+// SetupTwoFactor 生成两步验证密钥，返回 otpauth 链接供客户端生成二维码
+func SetupTwoFactor(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	setup, err := services.SetupTwoFactor(uint64(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": setup,
+	})
+}
+
+// EnableTwoFactor 提交验证码完成两步验证绑定，返回恢复码
+func EnableTwoFactor(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	codes, err := services.EnableTwoFactor(uint64(userID), req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "两步验证已启用，请妥善保存恢复码",
+		"data":    models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+func DisableTwoFactor(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.DisableTwoFactor(uint64(userID), &req); err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "两步验证已关闭",
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	codes, err := services.RegenerateRecoveryCodes(uint64(userID), req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// respondTwoFactorError 验证码错误返回 400，其余错误返回 500
+func respondTwoFactorError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
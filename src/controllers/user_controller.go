@@ -2,9 +2,11 @@ package controllers
 
 import (
 	"OptiOJ/src/config"
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 
+	"errors"
 	"net/http"
 	"time"
 	"unicode"
@@ -114,11 +116,10 @@ func RegisterUser(c *gin.Context) {
 	}
 
 	// 存储令牌信息到 Redis
-	accessSessionKey := "access_token:" + accessToken
 	refreshSessionKey := "refresh_token:" + refreshToken
 
 	// 访问令牌有效期2小时
-	if err := config.RedisClient.Set(c, accessSessionKey, userID, 2*time.Hour).Err(); err != nil {
+	if err := services.SaveAccessToken(c, accessToken, userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "存储访问令牌失败"})
 		return
 	}
@@ -153,14 +154,69 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
+	// 防暴力破解：检查锁定、等待时间和人机验证
+	attempt, err := services.CheckLoginAttempt(req.AccountInfo, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查登录状态失败"})
+		return
+	}
+	if !allowLoginAttempt(c, attempt, req.CaptchaID) {
+		return
+	}
+
 	// 验证用户名和密码
 	user, err := services.ValidateLogin(req.AccountInfo, req.PassWord)
 	if err != nil {
-		services.RecordLogin(c, 0, "failed", err.Error())
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondLoginFailure(c, attempt, err)
+		return
+	}
+
+	// 失败次数在签发令牌后才清除，两步验证失败同样计入
+	finishLogin(c, user)
+}
+
+// allowLoginAttempt 根据防暴力破解检查的结果决定是否继续验证，拒绝时已经写入响应
+func allowLoginAttempt(c *gin.Context, attempt *services.LoginAttemptStatus, captchaID string) bool {
+	if attempt.Locked {
+		services.RecordLogin(c, attempt.UserID, "locked", "登录失败次数过多，已临时锁定")
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "登录失败次数过多，请稍后再试",
+			"retry_after": retryAfterSeconds(attempt.RetryAfter),
+		})
+		return false
+	}
+	if attempt.RetryAfter > 0 {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":            "登录尝试过于频繁，请稍后再试",
+			"retry_after":      retryAfterSeconds(attempt.RetryAfter),
+			"captcha_required": attempt.CaptchaRequired,
+		})
+		return false
+	}
+	if attempt.CaptchaRequired && !services.ConsumeLoginCaptcha(captchaID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "请先完成人机验证", "captcha_required": true})
+		return false
+	}
+	return true
+}
+
+// respondLoginFailure 记录登录失败并返回下次登录是否需要等待或人机验证
+func respondLoginFailure(c *gin.Context, attempt *services.LoginAttemptStatus, err error) {
+	services.RecordLogin(c, attempt.UserID, "failed", err.Error())
+	next, recordErr := services.RecordLoginFailure(attempt)
+	if recordErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "记录登录失败次数失败"})
 		return
 	}
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":            err.Error(),
+		"captcha_required": next.CaptchaRequired,
+		"retry_after":      retryAfterSeconds(next.RetryAfter),
+	})
+}
 
+// finishLogin 身份验证通过后检查封禁状态和两步验证，无需两步验证时直接签发令牌
+func finishLogin(c *gin.Context, user *models.User) {
 	// 检查用户是否被封禁
 	banned, reason, _ := services.IsUserBanned(uint(user.ID))
 	if banned {
@@ -169,6 +225,32 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
+	// 启用了两步验证或必须启用两步验证的账号，需要完成第二步验证后才签发令牌
+	challenge, err := services.BeginTwoFactorLogin(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建两步验证失败"})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message":                   "请输入两步验证码",
+			"two_factor_required":       true,
+			"challenge_token":           challenge.Token,
+			"two_factor_setup_required": challenge.SetupRequired,
+		})
+		return
+	}
+
+	completeLogin(c, user, nil)
+}
+
+// retryAfterSeconds 把等待时间转换为向上取整的秒数
+func retryAfterSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// completeLogin 签发令牌并保存会话，返回登录成功的响应；recoveryCodes 为登录过程中绑定两步验证时生成的恢复码
+func completeLogin(c *gin.Context, user *models.User, recoveryCodes []string) {
 	// 生成访问令牌和刷新令牌
 	accessToken, refreshToken, err := services.GenerateTokenPair(uint(user.ID))
 	if err != nil {
@@ -178,11 +260,10 @@ func LoginUser(c *gin.Context) {
 	}
 
 	// 存储令牌信息到 Redis
-	accessSessionKey := "access_token:" + accessToken
 	refreshSessionKey := "refresh_token:" + refreshToken
 
 	// 访问令牌有效期2小时
-	if err := config.RedisClient.Set(c, accessSessionKey, user.ID, 2*time.Hour).Err(); err != nil {
+	if err := services.SaveAccessToken(c, accessToken, uint(user.ID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "存储访问令牌失败"})
 		return
 	}
@@ -199,10 +280,17 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
+	// 批量生成或导入的账号首次登录需修改密码
+	if err := services.SyncPasswordChangeRequired(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "同步密码状态失败"})
+		return
+	}
+
 	// 记录成功登录
 	services.RecordLogin(c, uint(user.ID), "success", "")
+	services.ClearUserLoginFailures(uint(user.ID))
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"message": "登录成功",
 		"user": gin.H{
 			"id":       user.ID,
@@ -210,22 +298,108 @@ func LoginUser(c *gin.Context) {
 			"email":    user.Email,
 			"phone":    user.Phone,
 		},
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
+		"must_change_password": user.MustChangePassword,
+		"access_token":         accessToken,
+		"refresh_token":        refreshToken,
+	}
+	if recoveryCodes != nil {
+		resp["recovery_codes"] = recoveryCodes
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ChangePassword 修改密码，首次登录需修改密码的用户也可以调用
+func ChangePassword(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	// 密码强度验证
+	if !validatePassword(req.NewPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "密码强度不足，需要满足以下条件中的两项：1. 密码长度至少8位且同时包含数字和字母；2. 包含特殊符号；3. 同时包含大小写字母"})
+		return
+	}
+
+	if err := services.ChangePassword(userID, req.OldPassword, req.NewPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "密码修改成功",
 	})
 }
 
-func GetGlobalData(c *gin.Context) {
-	// 从请求头获取访问令牌
-	accessToken := c.GetHeader("Authorization")
+// ForgotPassword 找回密码，向账号绑定的邮箱或手机号发送重置验证码
+func ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	// 验证 captchaID
+	val, err := config.RedisClient.Get(c, req.CaptchaID).Result()
+	if err != nil || val != "geetest:result:success" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的 captchaID"})
+		return
+	}
+
+	if err := services.SendPasswordResetCode(&req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 无论账号是否存在都返回相同的结果
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "如果该账号存在，验证码已发送",
+	})
+}
+
+// ResetPassword 使用重置验证码设置新密码，成功后退出全部设备
+func ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	// 密码强度验证
+	if !validatePassword(req.NewPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "密码强度不足，需要满足以下条件中的两项：1. 密码长度至少8位且同时包含数字和字母；2. 包含特殊符号；3. 同时包含大小写字母"})
+		return
+	}
 
-	// 验证访问令牌并获取用户ID
-	userID, err := services.ValidateAccessToken(accessToken)
+	userID, err := services.ResetPassword(&req)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
+		if userID != 0 {
+			services.RecordLogin(c, userID, "reset_failed", err.Error())
+		}
+		if errors.Is(err, services.ErrInvalidResetCode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	services.RecordLogin(c, userID, "password_reset", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "密码已重置，请重新登录",
+	})
+}
+
+func GetGlobalData(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
 	// 查询用户信息
 	var user models.User
 	if err := config.DB.First(&user, userID).Error; err != nil {
@@ -244,26 +418,21 @@ func GetGlobalData(c *gin.Context) {
 	profile, _ := services.GetProfile(userID)
 
 	// 获取用户权限组信息
-	isAdmin, _ := services.IsAdmin(userID)
-	isSuperAdmin, _ := services.IsSuperAdmin(userID)
-	var role string
-	if isSuperAdmin {
-		role = "super_admin"
-	} else if isAdmin {
-		role = "admin"
-	} else {
+	role := middleware.AdminRole(c)
+	if role == "" {
 		role = "user"
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
-			"phone":    user.Phone,
-			"avatar":   avatarFilename,
-			"profile":  profile,
-			"role":     role,
+			"id":          user.ID,
+			"username":    user.Username,
+			"email":       user.Email,
+			"phone":       user.Phone,
+			"avatar":      avatarFilename,
+			"profile":     profile,
+			"role":        role,
+			"permissions": middleware.Permissions(c),
 		},
 	})
 }
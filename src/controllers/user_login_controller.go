@@ -1,24 +1,19 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetLoginHistory 获取登录历史
 func GetLoginHistory(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(currentUserID)
+	currentUserID := middleware.CurrentUserID(c)
+	canViewAll := middleware.HasPermission(c, models.SitePermUserView)
 
 	var req models.LoginHistoryRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -26,14 +21,14 @@ func GetLoginHistory(c *gin.Context) {
 		return
 	}
 
-	// 非管理员只能查看自己的登录历史
-	if !isAdmin && req.UserID != 0 && req.UserID != currentUserID {
+	// 无 user.view 权限时只能查看自己的登录历史
+	if !canViewAll && req.UserID != 0 && req.UserID != currentUserID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
 		return
 	}
 
-	// 非管理员查询时，强制只能查看自己的记录
-	if !isAdmin {
+	// 无权限查询时，强制只能查看自己的记录
+	if !canViewAll {
 		req.UserID = currentUserID
 	}
 
@@ -50,3 +45,60 @@ func GetLoginHistory(c *gin.Context) {
 		"page_size": req.PageSize,
 	})
 }
+
+// GetUserLoginIPs 管理员查看用户登录IP汇总
+func GetUserLoginIPs(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	summaries, err := services.GetLoginIPSummary(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"user_id": id,
+			"ips":     summaries,
+			"total":   len(summaries),
+		},
+	})
+}
+
+// GetLoginLocks 获取因连续登录失败被临时锁定的账号和IP
+func GetLoginLocks(c *gin.Context) {
+	locks, err := services.GetLoginLocks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取锁定列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": locks,
+	})
+}
+
+// UnlockLogin 解除账号或IP的登录锁定
+func UnlockLogin(c *gin.Context) {
+	var req models.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+		return
+	}
+
+	if err := services.UnlockLogin(&req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已解除锁定",
+	})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"OptiOJ/src/middleware"
 	"OptiOJ/src/models"
 	"OptiOJ/src/services"
 	"net/http"
@@ -11,20 +12,6 @@ import (
 
 // GetUserList 获取用户列表
 func GetUserList(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(currentUserID)
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	var req models.UserListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
@@ -47,19 +34,7 @@ func GetUserList(c *gin.Context) {
 
 // UpdateUser 更新用户信息
 func UpdateUser(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(currentUserID)
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	userID := c.Param("id")
 	if userID == "" {
@@ -79,7 +54,7 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	if err := services.UpdateUserInfo(uint(id), &req); err != nil {
+	if err := services.UpdateUserInfo(uint(id), &req, currentUserID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -89,19 +64,7 @@ func UpdateUser(c *gin.Context) {
 
 // BanUser 封禁用户
 func BanUser(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(currentUserID)
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	var req models.UserBanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -119,19 +82,7 @@ func BanUser(c *gin.Context) {
 
 // UnbanUser 解封用户
 func UnbanUser(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(currentUserID)
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
+	currentUserID := middleware.CurrentUserID(c)
 
 	userID := c.Param("id")
 	if userID == "" {
@@ -155,20 +106,6 @@ func UnbanUser(c *gin.Context) {
 
 // GenerateUsers 批量生成用户
 func GenerateUsers(c *gin.Context) {
-	// 验证管理员权限
-	accessToken := c.GetHeader("Authorization")
-	currentUserID, err := services.ValidateAccessToken(accessToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
-		return
-	}
-
-	isAdmin, _ := services.IsAdmin(currentUserID)
-	if !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
-		return
-	}
-
 	var req models.GenerateUsersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
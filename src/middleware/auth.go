@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"OptiOJ/src/models"
+	"OptiOJ/src/services"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 请求上下文中保存的认证信息
+const (
+	ContextUserID      = "user_id"      // 当前用户ID（uint）
+	ContextAdminRole   = "admin_role"   // 管理员角色：super_admin、admin，普通用户为空
+	ContextAccessToken = "access_token" // 当前请求使用的访问令牌
+	ContextPermissions = "permissions"  // 当前用户的网站权限（按需加载）
+
+	ContextPersonalToken = "personal_token" // 当前请求是否使用个人访问令牌
+)
+
+// ExtractToken 从 Authorization 请求头中取出令牌，兼容带 Bearer 前缀和不带前缀两种写法
+func ExtractToken(c *gin.Context) string {
+	header := strings.TrimSpace(c.GetHeader("Authorization"))
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return header
+}
+
+// personalTokenScopes 允许使用个人访问令牌的接口及所需的权限范围，键为 "方法 路由"；
+// 未列出的接口只接受 JWT 访问令牌
+var personalTokenScopes = map[string]string{
+	"GET /problems":                   models.TokenScopeProblemRead,
+	"GET /problems/:id":               models.TokenScopeProblemRead,
+	"GET /problems/difficulty-system": models.TokenScopeProblemRead,
+	"POST /submissions":               models.TokenScopeSubmit,
+	"POST /submissions/debug":         models.TokenScopeSubmit,
+	"GET /submissions":                models.TokenScopeSubmissionRead,
+	"GET /submissions/:id":            models.TokenScopeSubmissionRead,
+}
+
+// validatePersonalToken 验证个人访问令牌能否访问当前接口
+func validatePersonalToken(c *gin.Context, token string) (uint, error) {
+	scope, ok := personalTokenScopes[c.Request.Method+" "+c.FullPath()]
+	if !ok {
+		return 0, services.ErrTokenScopeDenied
+	}
+	return services.ValidatePersonalAccessToken(token, scope, c.ClientIP())
+}
+
+// authenticate 验证访问令牌和封禁状态，并把用户信息写入上下文。
+// 验证失败时已经写入响应并中止请求，返回 false
+func authenticate(c *gin.Context, validate func(string) (uint, error)) bool {
+	accessToken := ExtractToken(c)
+	personalToken := services.IsPersonalAccessToken(accessToken)
+
+	var userID uint
+	var err error
+	if personalToken {
+		userID, err = validatePersonalToken(c, accessToken)
+	} else {
+		userID, err = validate(accessToken)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrPasswordChangeRequired) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":                err.Error(),
+				"must_change_password": true,
+			})
+			return false
+		}
+		if errors.Is(err, services.ErrTokenScopeDenied) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return false
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "无效的访问令牌"})
+		return false
+	}
+
+	banned, reason, err := services.IsUserBanned(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "检查封禁状态失败"})
+		return false
+	}
+	if banned {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "账号已被封禁，原因：" + reason})
+		return false
+	}
+
+	c.Set(ContextUserID, userID)
+	c.Set(ContextAccessToken, accessToken)
+
+	// 个人访问令牌不携带管理员角色，上下文中的网站权限为空，例如管理员的令牌也只能查看自己的提交记录
+	if personalToken {
+		c.Set(ContextPersonalToken, true)
+		c.Set(ContextPermissions, []string{})
+		return true
+	}
+
+	adminRole, err := services.GetAdminRole(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "获取用户权限失败"})
+		return false
+	}
+	c.Set(ContextAdminRole, adminRole)
+	return true
+}
+
+// RequireAuth 要求请求携带有效的访问令牌
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticate(c, services.ValidateAccessToken) {
+			c.Next()
+		}
+	}
+}
+
+// RequirePasswordChangeAuth 与 RequireAuth 相同，但允许需要修改密码的用户通过，仅用于修改密码接口
+func RequirePasswordChangeAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticate(c, services.ValidatePasswordChangeToken) {
+			c.Next()
+		}
+	}
+}
+
+// OptionalAuth 携带令牌时解析用户信息，未携带或令牌无效时按游客处理
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken := ExtractToken(c)
+		if accessToken == "" {
+			c.Next()
+			return
+		}
+		personalToken := services.IsPersonalAccessToken(accessToken)
+
+		var userID uint
+		var err error
+		if personalToken {
+			userID, err = validatePersonalToken(c, accessToken)
+		} else {
+			userID, err = services.ValidateAccessToken(accessToken)
+		}
+		if err != nil {
+			c.Next()
+			return
+		}
+		banned, _, err := services.IsUserBanned(userID)
+		if err != nil || banned {
+			c.Next()
+			return
+		}
+		if personalToken {
+			c.Set(ContextUserID, userID)
+			c.Set(ContextAccessToken, accessToken)
+			c.Set(ContextPersonalToken, true)
+			c.Set(ContextPermissions, []string{})
+			c.Next()
+			return
+		}
+		adminRole, err := services.GetAdminRole(userID)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		c.Set(ContextUserID, userID)
+		c.Set(ContextAdminRole, adminRole)
+		c.Set(ContextAccessToken, accessToken)
+		c.Next()
+	}
+}
+
+// RequirePermission 要求当前用户拥有指定的网站权限，需在 RequireAuth 之后使用
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSuperAdmin 要求当前用户为超级管理员，需在 RequireAuth 之后使用
+func RequireSuperAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsSuperAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentUserID 获取当前用户ID，未登录时返回 0
+func CurrentUserID(c *gin.Context) uint {
+	return c.GetUint(ContextUserID)
+}
+
+// AdminRole 获取当前用户的管理员角色，非管理员返回空字符串
+func AdminRole(c *gin.Context) string {
+	return c.GetString(ContextAdminRole)
+}
+
+// IsAdmin 判断当前用户是否为管理员（含超级管理员）
+func IsAdmin(c *gin.Context) bool {
+	role := AdminRole(c)
+	return role == "admin" || role == "super_admin"
+}
+
+// IsSuperAdmin 判断当前用户是否为超级管理员
+func IsSuperAdmin(c *gin.Context) bool {
+	return AdminRole(c) == "super_admin"
+}
+
+// IsPersonalToken 判断当前请求是否使用个人访问令牌认证，这类请求只拥有令牌的权限范围
+func IsPersonalToken(c *gin.Context) bool {
+	return c.GetBool(ContextPersonalToken)
+}
+
+// Permissions 获取当前用户的网站权限，首次调用时查询并缓存在上下文中；未登录或查询失败时返回空列表
+func Permissions(c *gin.Context) []string {
+	if value, ok := c.Get(ContextPermissions); ok {
+		return value.([]string)
+	}
+
+	permissions := []string{}
+	if userID := CurrentUserID(c); userID != 0 {
+		if loaded, err := services.GetUserSitePermissions(userID); err == nil {
+			permissions = loaded
+		}
+	}
+	c.Set(ContextPermissions, permissions)
+	return permissions
+}
+
+// HasPermission 判断当前用户是否拥有指定的网站权限
+func HasPermission(c *gin.Context, permission string) bool {
+	for _, p := range Permissions(c) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
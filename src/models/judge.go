@@ -83,6 +83,53 @@ type SubmissionDetail struct {
 	Problem *Problem      `json:"problem"`
 	User    *User         `json:"user"`
 	Results []JudgeResult `json:"results,omitempty" gorm:"foreignKey:SubmissionID"`
+
+	Feedback *SubmissionFeedback `json:"feedback,omitempty" gorm:"-"` // 作业提交的批阅反馈
+}
+
+// SubmissionReview 作业提交批阅（人工评分）
+type SubmissionReview struct {
+	SubmissionID uint64     `json:"submission_id" gorm:"primaryKey"`
+	ReviewerID   uint64     `json:"reviewer_id"`
+	ManualScore  *int       `json:"manual_score"` // 人工评分，为空表示沿用自动评分
+	Rationale    string     `json:"rationale"`    // 评分理由
+	Reviewed     bool       `json:"reviewed"`     // 是否已批阅
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// SubmissionComment 作业提交代码行评论
+type SubmissionComment struct {
+	ID           uint64    `json:"id"`
+	SubmissionID uint64    `json:"submission_id"`
+	UserID       uint64    `json:"user_id"`
+	Username     string    `json:"username" gorm:"->"` // 评论者用户名，仅查询时填充
+	LineStart    int       `json:"line_start"`         // 起始行号（从1开始）
+	LineEnd      int       `json:"line_end"`           // 结束行号
+	Content      string    `json:"content"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SubmissionFeedback 作业提交的批阅反馈
+type SubmissionFeedback struct {
+	Review   *SubmissionReview   `json:"review"`   // 批阅信息，未批阅时为空
+	Comments []SubmissionComment `json:"comments"` // 代码行评论
+}
+
+// AddSubmissionCommentRequest 添加代码行评论请求
+type AddSubmissionCommentRequest struct {
+	LineStart int    `json:"line_start" binding:"required,min=1"`
+	LineEnd   int    `json:"line_end" binding:"omitempty,min=1"` // 为空表示与起始行相同
+	Content   string `json:"content" binding:"required,max=2000"`
+}
+
+// GradeSubmissionRequest 人工评分请求
+type GradeSubmissionRequest struct {
+	ManualScore *int   `json:"manual_score" binding:"omitempty,min=0"` // 为空表示取消人工评分，沿用自动评分
+	Rationale   string `json:"rationale" binding:"max=2000"`
+	Reviewed    *bool  `json:"reviewed"` // 为空时默认标记为已批阅
 }
 
 // JudgeConfig 判题配置
@@ -0,0 +1,18 @@
+package models
+
+// JSONWebKey JWKS 中的单个公钥（RFC 7517），只包含公钥参数
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"` // EdDSA：曲线名称
+	X         string `json:"x,omitempty"`   // EdDSA：公钥
+	N         string `json:"n,omitempty"`   // RS256：模数
+	E         string `json:"e,omitempty"`   // RS256：指数
+}
+
+// JSONWebKeySet JWKS 响应
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
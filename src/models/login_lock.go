@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// 登录锁定的对象类型
+const (
+	LoginLockAccount = "account" // 按账号锁定
+	LoginLockIP      = "ip"      // 按IP锁定
+)
+
+// LoginLock 因连续登录失败被临时锁定的账号或IP
+type LoginLock struct {
+	Type        string    `json:"type"`               // account 或 ip
+	Value       string    `json:"value"`              // 账号标识或IP地址
+	UserID      uint64    `json:"user_id,omitempty"`  // 锁定账号对应的用户
+	Username    string    `json:"username,omitempty"` // 锁定账号对应的用户名
+	Failures    int64     `json:"failures"`           // 当前统计窗口内的失败次数
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// UnlockLoginRequest 解除登录锁定请求
+type UnlockLoginRequest struct {
+	Type  string `json:"type" binding:"required,oneof=account ip"`
+	Value string `json:"value" binding:"required"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// UserIdentity 用户关联的外部身份，同一身份提供方的同一用户只能关联一个账号
+type UserIdentity struct {
+	ID          uint64     `json:"id"`
+	UserID      uint64     `json:"user_id"`
+	Provider    string     `json:"provider"` // 身份提供方的 issuer
+	Subject     string     `json:"subject"`  // 身份提供方中的用户标识
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// OIDCProviderInfo 单点登录配置信息，供前端决定是否显示登录按钮
+type OIDCProviderInfo struct {
+	Enabled bool   `json:"enabled"`
+	Name    string `json:"name"`
+}
+
+// OIDCCallbackRequest 身份提供方回调前端后，前端提交的授权码
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+// 组织类型
+const (
+	OrganizationTypeSchool = "school" // 学校
+	OrganizationTypeGrade  = "grade"  // 年级
+	OrganizationTypeClass  = "class"  // 班级
+	OrganizationTypeOther  = "other"  // 其他
+)
+
+// Organization 组织，按 ParentID 构成学校、年级、班级的层级结构
+type Organization struct {
+	ID          uint64    `json:"id"`
+	ParentID    *uint64   `json:"parent_id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	CreatedBy   uint64    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// OrganizationAdmin 组织管理员
+type OrganizationAdmin struct {
+	OrgID     uint64    `json:"org_id" gorm:"primaryKey"`
+	UserID    uint64    `json:"user_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationProblem 组织题库中的题目
+type OrganizationProblem struct {
+	OrgID     uint64    `json:"org_id" gorm:"primaryKey"`
+	ProblemID uint64    `json:"problem_id" gorm:"primaryKey"`
+	AddedBy   uint64    `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationNode 组织树节点
+type OrganizationNode struct {
+	Organization
+	TeamCount int                 `json:"team_count"`
+	Children  []*OrganizationNode `json:"children"`
+}
+
+// OrganizationAdminInfo 组织管理员信息
+type OrganizationAdminInfo struct {
+	UserID    uint64    `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationProblemInfo 组织题库题目信息
+type OrganizationProblemInfo struct {
+	ProblemID  uint64    `json:"problem_id"`
+	Title      string    `json:"title"`
+	Difficulty string    `json:"difficulty"`
+	AddedBy    uint64    `json:"added_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SchoolMemberInfo 关联到学校组织的用户信息
+type SchoolMemberInfo struct {
+	UserID         uint64 `json:"user_id"`
+	Username       string `json:"username"`
+	RealName       string `json:"real_name"`
+	SchoolVerified bool   `json:"school_verified"`
+}
+
+// CreateOrganizationRequest 创建组织请求
+type CreateOrganizationRequest struct {
+	ParentID    *uint64 `json:"parent_id"`
+	Name        string  `json:"name" binding:"required,max=100"`
+	Type        string  `json:"type" binding:"required,oneof=school grade class other"`
+	Description string  `json:"description"`
+}
+
+// UpdateOrganizationRequest 更新组织请求
+type UpdateOrganizationRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// OrganizationUserRequest 指定用户的组织操作请求（添加管理员等）
+type OrganizationUserRequest struct {
+	UserID uint64 `json:"user_id" binding:"required"`
+}
+
+// OrganizationProblemRequest 组织题库添加题目请求
+type OrganizationProblemRequest struct {
+	ProblemID uint64 `json:"problem_id" binding:"required"`
+}
+
+// SetTeamOrganizationRequest 设置团队所属组织请求，OrgID 为空表示移出组织
+type SetTeamOrganizationRequest struct {
+	OrgID *uint64 `json:"org_id"`
+}
+
+// VerifySchoolMemberRequest 认证学校关联请求
+type VerifySchoolMemberRequest struct {
+	Verified *bool `json:"verified" binding:"required"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// 个人访问令牌的权限范围
+const (
+	TokenScopeSubmit         = "submit"          // 提交代码和在线调试
+	TokenScopeSubmissionRead = "submission.read" // 查看自己的提交记录
+	TokenScopeProblemRead    = "problem.read"    // 查看题目
+)
+
+// AllTokenScopes 全部个人访问令牌权限范围
+var AllTokenScopes = []string{
+	TokenScopeSubmit,
+	TokenScopeSubmissionRead,
+	TokenScopeProblemRead,
+}
+
+// PersonalAccessToken 个人访问令牌，供命令行工具和编辑器插件长期使用；只保存令牌的摘要
+type PersonalAccessToken struct {
+	ID          uint64     `json:"id"`
+	UserID      uint64     `json:"user_id"`
+	Name        string     `json:"name"`
+	TokenHash   string     `json:"-"`            // 令牌的 SHA-256 摘要
+	TokenPrefix string     `json:"token_prefix"` // 令牌开头几位，便于用户辨认
+	Scopes      string     `json:"scopes"`       // 逗号分隔的权限范围
+	ExpiresAt   *time.Time `json:"expires_at"`   // 为空表示永不过期
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PersonalAccessTokenInfo 个人访问令牌信息
+type PersonalAccessTokenInfo struct {
+	ID          uint64     `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreatePersonalAccessTokenRequest 创建个人访问令牌请求
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=50"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=submit submission.read problem.read"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=365"` // 0 表示永不过期
+}
+
+// CreatePersonalAccessTokenResponse 创建个人访问令牌的响应，完整令牌只在创建时返回一次
+type CreatePersonalAccessTokenResponse struct {
+	Token string                  `json:"token"`
+	Info  PersonalAccessTokenInfo `json:"info"`
+}
@@ -19,6 +19,10 @@ type Profile struct {
 	RealName string     `json:"real_name"`         // 真实姓名
 	CreateAt time.Time  `json:"create_at"`         // 创建时间
 	UpdateAt time.Time  `json:"update_at"`         // 更新时间
+
+	SchoolOrgID    *uint64 `json:"school_org_id"`   // 关联的学校组织
+	SchoolVerified bool    `json:"school_verified"` // 学校关联是否已由组织管理员认证
+	StudentID      string  `json:"student_id"`      // 学号
 }
 
 // UnmarshalJSON 实现自定义的 JSON 解析
@@ -85,6 +89,8 @@ type UpdateProfileRequest struct {
 	Province string `json:"province"`
 	City     string `json:"city"`
 	RealName string `json:"real_name"`
+
+	SchoolOrgID *uint64 `json:"school_org_id"` // 关联学校组织，设置后学校名称取组织名称并等待认证
 }
 
 // ActivityLevel 活跃度等级
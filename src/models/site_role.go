@@ -0,0 +1,124 @@
+package models
+
+import "time"
+
+// 网站内置角色，super_admin 和 admin 由 admins 表记录，其余角色通过 user_site_roles 分配
+const (
+	SiteRoleSuperAdmin     = "super_admin"     // 超级管理员
+	SiteRoleAdmin          = "admin"           // 管理员
+	SiteRoleProblemSetter  = "problem_setter"  // 出题人
+	SiteRoleContestManager = "contest_manager" // 比赛管理员
+	SiteRoleUserModerator  = "user_moderator"  // 用户管理员
+	SiteRoleAuditor        = "auditor"         // 审计员
+)
+
+// 网站权限
+const (
+	SitePermProblemRead    = "problem.read"        // 查看全部题目（含未公开题目）
+	SitePermProblemCreate  = "problem.create"      // 创建题目
+	SitePermProblemEdit    = "problem.edit"        // 编辑题目、切换难度等级系统
+	SitePermProblemDelete  = "problem.delete"      // 删除题目
+	SitePermProblemReview  = "problem.review"      // 审核团队题目进入公共题库
+	SitePermTagManage      = "tag.manage"          // 管理标签和标签分类
+	SitePermTestcaseRead   = "testcase.read"       // 查看测试用例
+	SitePermTestcaseWrite  = "testcase.write"      // 上传和删除测试用例
+	SitePermSubmissionView = "submission.view_all" // 查看全部提交记录
+	SitePermContestManage  = "contest.manage"      // 管理比赛（预留给比赛模块）
+	SitePermUserView       = "user.view"           // 查看用户列表、登录历史和登录IP
+	SitePermUserEdit       = "user.edit"           // 修改用户信息
+	SitePermUserBan        = "user.ban"            // 封禁和解封用户
+	SitePermUserCreate     = "user.create"         // 批量生成用户账号
+	SitePermOrgManage      = "org.manage"          // 创建顶级组织，管理任意组织和团队
+	SitePermAdminView      = "admin.view"          // 查看管理员列表
+	SitePermRoleManage     = "role.manage"         // 管理网站角色并为用户分配角色
+)
+
+// AllSitePermissions 全部网站权限
+var AllSitePermissions = []string{
+	SitePermProblemRead,
+	SitePermProblemCreate,
+	SitePermProblemEdit,
+	SitePermProblemDelete,
+	SitePermProblemReview,
+	SitePermTagManage,
+	SitePermTestcaseRead,
+	SitePermTestcaseWrite,
+	SitePermSubmissionView,
+	SitePermContestManage,
+	SitePermUserView,
+	SitePermUserEdit,
+	SitePermUserBan,
+	SitePermUserCreate,
+	SitePermOrgManage,
+	SitePermAdminView,
+	SitePermRoleManage,
+}
+
+// DefaultSiteRoles 内置角色及默认权限，可以覆盖除超级管理员外的内置角色权限
+var DefaultSiteRoles = []SiteRoleInfo{
+	{Role: SiteRoleSuperAdmin, Name: "超级管理员", BuiltIn: true, Permissions: AllSitePermissions},
+	{Role: SiteRoleAdmin, Name: "管理员", BuiltIn: true, Permissions: []string{
+		SitePermProblemRead, SitePermProblemCreate, SitePermProblemEdit, SitePermProblemDelete,
+		SitePermProblemReview, SitePermTagManage, SitePermTestcaseRead, SitePermTestcaseWrite,
+		SitePermSubmissionView, SitePermContestManage, SitePermUserView, SitePermUserEdit,
+		SitePermUserBan, SitePermUserCreate, SitePermOrgManage, SitePermAdminView,
+	}},
+	{Role: SiteRoleProblemSetter, Name: "出题人", BuiltIn: true, Permissions: []string{
+		SitePermProblemRead, SitePermProblemCreate, SitePermProblemEdit,
+		SitePermTagManage, SitePermTestcaseRead, SitePermTestcaseWrite,
+	}},
+	{Role: SiteRoleContestManager, Name: "比赛管理员", BuiltIn: true, Permissions: []string{
+		SitePermContestManage, SitePermProblemRead, SitePermTestcaseRead, SitePermSubmissionView,
+	}},
+	{Role: SiteRoleUserModerator, Name: "用户管理员", BuiltIn: true, Permissions: []string{
+		SitePermUserView, SitePermUserEdit, SitePermUserBan,
+	}},
+	{Role: SiteRoleAuditor, Name: "审计员", BuiltIn: true, Permissions: []string{
+		SitePermProblemRead, SitePermTestcaseRead, SitePermSubmissionView,
+		SitePermUserView, SitePermAdminView,
+	}},
+}
+
+// SiteRole 网站自定义角色，或对内置角色权限的覆盖
+type SiteRole struct {
+	Role        string    `json:"role" gorm:"primaryKey"`
+	Name        string    `json:"name"`
+	Permissions string    `json:"permissions"` // 逗号分隔的权限列表
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserSiteRole 用户被分配的网站角色
+type UserSiteRole struct {
+	UserID    uint64    `json:"user_id" gorm:"primaryKey"`
+	Role      string    `json:"role" gorm:"primaryKey"`
+	GrantedBy uint64    `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SiteRoleInfo 网站角色信息
+type SiteRoleInfo struct {
+	Role        string   `json:"role"`
+	Name        string   `json:"name"`
+	BuiltIn     bool     `json:"built_in"` // 是否为内置角色
+	Permissions []string `json:"permissions"`
+}
+
+// SaveSiteRoleRequest 创建或更新网站角色请求
+type SaveSiteRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Permissions []string `json:"permissions" binding:"dive,oneof=problem.read problem.create problem.edit problem.delete problem.review tag.manage testcase.read testcase.write submission.view_all contest.manage user.view user.edit user.ban user.create org.manage admin.view role.manage"`
+}
+
+// SetUserSiteRolesRequest 设置用户网站角色请求，会替换用户现有的角色
+type SetUserSiteRolesRequest struct {
+	Roles []string `json:"roles" binding:"max=20"`
+}
+
+// UserSiteRolesResponse 用户的网站角色和生效的权限
+type UserSiteRolesResponse struct {
+	UserID      uint64   `json:"user_id"`
+	AdminRole   string   `json:"admin_role"` // 管理员角色，非管理员为空
+	Roles       []string `json:"roles"`      // 通过角色分配获得的角色
+	Permissions []string `json:"permissions"`
+}
@@ -8,9 +8,42 @@ type Team struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Avatar      string    `json:"avatar,omitempty"`
+	OrgID       *uint64   `json:"org_id"` // 所属组织
 	CreatedBy   uint64    `json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	ArchivedAt *time.Time `json:"archived_at"` // 归档时间，为空表示未归档
+	ArchivedBy *uint64    `json:"archived_by"`
+}
+
+// 所有权转让状态
+const (
+	OwnershipTransferPending   = "pending"   // 等待确认
+	OwnershipTransferAccepted  = "accepted"  // 已接受
+	OwnershipTransferRejected  = "rejected"  // 已拒绝
+	OwnershipTransferCancelled = "cancelled" // 已取消
+)
+
+// TeamOwnershipTransfer 团队所有权转让
+type TeamOwnershipTransfer struct {
+	ID         uint64    `json:"id"`
+	TeamID     uint64    `json:"team_id"`
+	FromUserID uint64    `json:"from_user_id"`
+	ToUserID   uint64    `json:"to_user_id"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TransferTeamOwnershipRequest 发起所有权转让请求
+type TransferTeamOwnershipRequest struct {
+	UserID uint64 `json:"user_id" binding:"required"` // 接收方用户ID，必须是团队成员
+}
+
+// RespondOwnershipTransferRequest 确认或拒绝所有权转让请求
+type RespondOwnershipTransferRequest struct {
+	Accept *bool `json:"accept" binding:"required"`
 }
 
 // TeamMember 团队成员
@@ -19,6 +52,8 @@ type TeamMember struct {
 	UserID   uint64    `json:"user_id"`
 	Role     string    `json:"role"`
 	JoinedAt time.Time `json:"joined_at"`
+
+	InvitationID *uint64 `json:"invitation_id"` // 加入时使用的邀请
 }
 
 // TeamAssignment 团队作业
@@ -32,6 +67,31 @@ type TeamAssignment struct {
 	CreatedBy   uint64    `json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	AllowLate          bool       `json:"allow_late"`           // 是否允许迟交
+	LatePenaltyPercent int        `json:"late_penalty_percent"` // 每个计时单位扣除的分数百分比
+	LatePenaltyUnit    string     `json:"late_penalty_unit"`    // 迟交扣分计时单位：hour-按小时，day-按天
+	LateCutoff         *time.Time `json:"late_cutoff"`          // 迟交截止时间，为空表示不限
+
+	IsPublished bool       `json:"is_published"` // 是否已发布，未发布的作业对普通成员不可见
+	PublishAt   *time.Time `json:"publish_at"`   // 定时发布时间
+}
+
+// 迟交扣分计时单位
+const (
+	LatePenaltyUnitHour = "hour"
+	LatePenaltyUnitDay  = "day"
+)
+
+// TeamAssignmentExtension 作业截止时间延期（按成员）
+type TeamAssignmentExtension struct {
+	AssignmentID uint64    `json:"assignment_id"`
+	UserID       uint64    `json:"user_id"`
+	EndTime      time.Time `json:"end_time"` // 延期后的截止时间
+	Reason       string    `json:"reason"`
+	GrantedBy    uint64    `json:"granted_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // TeamAssignmentProblem 团队作业题目
@@ -44,6 +104,35 @@ type TeamAssignmentProblem struct {
 	Score         int     `json:"score"`
 }
 
+// TeamAssignmentTemplate 作业模板，时间以相对开始时间的分钟数保存
+type TeamAssignmentTemplate struct {
+	ID                 uint64    `json:"id"`
+	TeamID             uint64    `json:"team_id"`
+	Name               string    `json:"name"` // 模板名称
+	Title              string    `json:"title"`
+	Description        string    `json:"description"`
+	DurationMinutes    int       `json:"duration_minutes"` // 作业持续时间（分钟）
+	AllowLate          bool      `json:"allow_late"`
+	LatePenaltyPercent int       `json:"late_penalty_percent"`
+	LatePenaltyUnit    string    `json:"late_penalty_unit"`
+	LateCutoffMinutes  *int      `json:"late_cutoff_minutes"` // 迟交截止时间相对结束时间的分钟数，为空表示不限
+	CreatedBy          uint64    `json:"created_by"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+
+	Problems []TeamAssignmentTemplateProblem `json:"problems,omitempty" gorm:"foreignKey:TemplateID"`
+}
+
+// TeamAssignmentTemplateProblem 作业模板题目
+type TeamAssignmentTemplateProblem struct {
+	TemplateID    uint64  `json:"template_id"`
+	ProblemID     uint64  `json:"problem_id"`
+	ProblemType   string  `json:"problem_type"`    // 题目类型：global-全局题目，team-团队题目
+	TeamProblemID *uint64 `json:"team_problem_id"` // 团队题目ID，当problem_type为team时有效
+	OrderIndex    int     `json:"order_index"`
+	Score         int     `json:"score"`
+}
+
 // TeamProblemList 团队题单
 type TeamProblemList struct {
 	ID          uint64    `json:"id"`
@@ -64,6 +153,50 @@ type TeamProblemListItem struct {
 	Note       string `json:"note"`
 }
 
+// 题单题目完成状态
+const (
+	ProblemProgressSolved    = "solved"    // 已通过
+	ProblemProgressAttempted = "attempted" // 已尝试未通过
+	ProblemProgressUntouched = "untouched" // 未提交
+)
+
+// ProblemListItemProgress 题单题目及当前用户的完成状态
+type ProblemListItemProgress struct {
+	TeamProblemListItem
+	Title  string `json:"title"`
+	Status string `json:"status,omitempty" gorm:"-"` // 未登录时为空
+}
+
+// TeamProblemListDetail 题单详情，包含题目和当前用户的完成情况
+type TeamProblemListDetail struct {
+	TeamProblemList
+	Items          []ProblemListItemProgress `json:"items"`
+	TotalCount     int                       `json:"total_count"`
+	SolvedCount    int                       `json:"solved_count"`
+	AttemptedCount int                       `json:"attempted_count"`
+	CompletionRate float64                   `json:"completion_rate"` // 完成百分比
+}
+
+// ProblemListMemberProgress 成员在题单上的完成情况
+type ProblemListMemberProgress struct {
+	Rank           int               `json:"rank"`
+	UserID         uint64            `json:"user_id"`
+	Username       string            `json:"username"`
+	Nickname       string            `json:"nickname,omitempty"`
+	SolvedCount    int               `json:"solved_count"`
+	AttemptedCount int               `json:"attempted_count"`
+	CompletionRate float64           `json:"completion_rate"`
+	LastSolvedAt   *time.Time        `json:"last_solved_at"`  // 最后一道题首次通过的时间，用于同分排序
+	Items          map[uint64]string `json:"items,omitempty"` // 题目ID -> 完成状态
+}
+
+// ProblemListProgressResponse 题单成员进度与排行榜
+type ProblemListProgressResponse struct {
+	ListID     uint64                      `json:"list_id"`
+	TotalCount int                         `json:"total_count"`
+	Members    []ProblemListMemberProgress `json:"members"`
+}
+
 // TeamInvitation 团队邀请
 type TeamInvitation struct {
 	ID        uint64    `json:"id"`
@@ -72,6 +205,32 @@ type TeamInvitation struct {
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedBy uint64    `json:"created_by"`
 	CreatedAt time.Time `json:"created_at"`
+
+	MaxUses   *int       `json:"max_uses"`   // 最大使用次数，为空表示不限
+	UsedCount int        `json:"used_count"` // 已使用次数
+	Role      string     `json:"role"`       // 加入后的角色
+	Email     string     `json:"email"`      // 限定可使用的邮箱，空表示不限
+	RevokedAt *time.Time `json:"revoked_at"` // 撤销时间
+}
+
+// CreateTeamInvitationRequest 创建团队邀请请求，所有字段均可省略
+type CreateTeamInvitationRequest struct {
+	MaxUses   *int       `json:"max_uses" binding:"omitempty,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"` // 过期时间，默认 7 天后
+	Role      string     `json:"role"`       // 加入后的角色，默认为成员
+	Email     string     `json:"email" binding:"omitempty,email,max=100"`
+}
+
+// TeamInvitationInfo 团队邀请列表项
+type TeamInvitationInfo struct {
+	TeamInvitation
+	CreatorName string `json:"creator_name"`
+	Active      bool   `json:"active" gorm:"-"` // 是否仍可使用
+}
+
+// TeamInvitationListRequest 团队邀请列表请求
+type TeamInvitationListRequest struct {
+	All bool `form:"all"` // 是否包含已过期、已撤销和已用完的邀请
 }
 
 // CreateTeamRequest 创建团队请求
@@ -93,7 +252,16 @@ type CreateAssignmentRequest struct {
 	Description string    `json:"description"`
 	StartTime   time.Time `json:"start_time" binding:"required"`
 	EndTime     time.Time `json:"end_time" binding:"required"`
-	Problems    []struct {
+
+	AllowLate          bool       `json:"allow_late"`                                           // 是否允许迟交
+	LatePenaltyPercent int        `json:"late_penalty_percent" binding:"min=0,max=100"`         // 每个计时单位扣除的分数百分比
+	LatePenaltyUnit    string     `json:"late_penalty_unit" binding:"omitempty,oneof=hour day"` // 迟交扣分计时单位，默认为 day
+	LateCutoff         *time.Time `json:"late_cutoff"`                                          // 迟交截止时间，为空表示不限
+
+	Hidden    bool       `json:"hidden"`     // 是否创建为隐藏作业（不发布）
+	PublishAt *time.Time `json:"publish_at"` // 定时发布时间，设置后作业在该时间自动发布
+
+	Problems []struct {
 		ProblemID     uint64 `json:"problem_id" binding:"required"`
 		ProblemType   string `json:"problem_type" binding:"required,oneof=global team"` // 题目类型：global-全局题目，team-团队题目
 		TeamProblemID uint64 `json:"team_problem_id,omitempty"`                         // 团队题目ID，当problem_type为team时必填
@@ -108,7 +276,17 @@ type UpdateAssignmentRequest struct {
 	Description string    `json:"description"`
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
-	Problems    []struct {
+
+	AllowLate          *bool      `json:"allow_late"`                                             // 是否允许迟交
+	LatePenaltyPercent *int       `json:"late_penalty_percent" binding:"omitempty,min=0,max=100"` // 每个计时单位扣除的分数百分比
+	LatePenaltyUnit    string     `json:"late_penalty_unit" binding:"omitempty,oneof=hour day"`   // 迟交扣分计时单位
+	LateCutoff         *time.Time `json:"late_cutoff"`                                            // 迟交截止时间
+	ClearLateCutoff    bool       `json:"clear_late_cutoff"`                                      // 是否清除迟交截止时间
+
+	Hidden    *bool      `json:"hidden"`     // 是否隐藏作业，false 表示立即发布
+	PublishAt *time.Time `json:"publish_at"` // 定时发布时间，设置后作业改为在该时间自动发布
+
+	Problems []struct {
 		ProblemID     uint64 `json:"problem_id"`
 		ProblemType   string `json:"problem_type" binding:"omitempty,oneof=global team"` // 题目类型：global-全局题目，team-团队题目
 		TeamProblemID uint64 `json:"team_problem_id,omitempty"`                          // 团队题目ID，当problem_type为team时必填
@@ -148,6 +326,9 @@ type TeamListRequest struct {
 	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
 	Keyword  string `form:"keyword"`
 	Scope    string `form:"scope" binding:"omitempty,oneof=all joined"` // 查询范围：all-所有团队，joined-已加入的团队
+
+	OrgID          uint64 `form:"org_id"`           // 按所属组织筛选
+	IncludeSubOrgs bool   `form:"include_sub_orgs"` // 按组织筛选时是否包含下级组织的团队
 }
 
 // TeamListResponse 团队列表响应
@@ -173,6 +354,9 @@ type TeamDetail struct {
 	UserRole    string         `json:"user_role,omitempty"`
 	IsJoined    bool           `json:"is_joined"`
 	Owner       *TeamOwnerInfo `json:"owner,omitempty"`
+
+	ActivityURL string `json:"activity_url,omitempty"` // 团队动态接口，团队成员可见
+	StatsURL    string `json:"stats_url,omitempty"`    // 团队统计接口，拥有查看提交权限时可见
 }
 
 // TeamAvatar 团队头像
@@ -206,6 +390,8 @@ type TeamMemberInfo struct {
 	Role     string    `json:"role"`
 	JoinedAt time.Time `json:"joined_at"`
 	Nickname string    `json:"nickname,omitempty"` // 团队内名称
+
+	InvitationID *uint64 `json:"invitation_id"` // 加入时使用的邀请
 }
 
 // TeamMemberListResponse 团队成员列表响应
@@ -235,8 +421,8 @@ type AvailableProblemListRequest struct {
 	TeamID   uint64 `form:"team_id" binding:"required"`
 	Page     int    `form:"page" binding:"required,min=1"`
 	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
-	Keyword  string `form:"keyword"`                                        // 搜索关键字
-	Type     string `form:"type" binding:"omitempty,oneof=all global team"` // 题目类型：all-所有题目，global-全局题目，team-团队题目
+	Keyword  string `form:"keyword"`                                            // 搜索关键字
+	Type     string `form:"type" binding:"omitempty,oneof=all global team org"` // 题目类型：all-所有题目，global-全局题目，team-团队题目，org-所属组织题库
 }
 
 // AvailableProblemInfo 可用题目信息
@@ -373,6 +559,8 @@ type AssignmentSubmissionInfo struct {
 	TimeUsed     int       `json:"time_used"`     // 运行时间（毫秒）
 	MemoryUsed   int       `json:"memory_used"`   // 内存使用（KB）
 	Score        int       `json:"score"`         // 题目分值
+	Reviewed     bool      `json:"reviewed"`      // 是否已批阅
+	ManualScore  *int      `json:"manual_score"`  // 人工评分，为空表示沿用自动评分
 	CreatedAt    time.Time `json:"created_at"`    // 提交时间
 }
 
@@ -383,3 +571,89 @@ type GetAssignmentSubmissionsResponse struct {
 	Page        int                        `json:"page"`
 	PageSize    int                        `json:"page_size"`
 }
+
+// GetAssignmentGradebookRequest 获取作业成绩表请求
+type GetAssignmentGradebookRequest struct {
+	AssignmentID uint64 `form:"assignment_id" binding:"required"`
+	TeamID       uint64 `form:"team_id" binding:"required"`
+}
+
+// ExportAssignmentGradebookRequest 导出作业成绩表请求
+type ExportAssignmentGradebookRequest struct {
+	AssignmentID uint64 `form:"assignment_id" binding:"required"`
+	TeamID       uint64 `form:"team_id" binding:"required"`
+	Format       string `form:"format" binding:"omitempty,oneof=csv xlsx"` // 导出格式：csv、xlsx，默认为csv
+}
+
+// GradebookProblem 成绩表题目列
+type GradebookProblem struct {
+	ProblemID   uint64 `json:"problem_id"`   // 题目ID
+	ProblemType string `json:"problem_type"` // 题目类型：global-全局题目，team-团队题目
+	Title       string `json:"title"`        // 题目标题
+	Score       int    `json:"score"`        // 题目分值
+	OrderIndex  int    `json:"order_index"`  // 题目顺序
+}
+
+// GradebookCell 成绩表单元格
+type GradebookCell struct {
+	ProblemID       uint64     `json:"problem_id"`        // 题目ID
+	Status          string     `json:"status"`            // 状态：accepted-已通过，attempted-尝试过，untouched-未提交
+	LastStatus      string     `json:"last_status"`       // 最后一次提交的判题状态
+	Score           int        `json:"score"`             // 最佳得分
+	Attempts        int        `json:"attempts"`          // 提交次数
+	FirstAcceptedAt *time.Time `json:"first_accepted_at"` // 首次通过时间
+	IsLate          bool       `json:"is_late"`           // 最佳得分是否来自迟交
+	PenaltyPercent  int        `json:"penalty_percent"`   // 最佳得分所扣除的百分比
+	ManuallyGraded  bool       `json:"manually_graded"`   // 得分是否为人工评分
+}
+
+// GradebookRow 成绩表行（一个成员）
+type GradebookRow struct {
+	UserID      uint64          `json:"user_id"`      // 用户ID
+	Username    string          `json:"username"`     // 用户名
+	Nickname    string          `json:"nickname"`     // 团队内名称
+	Deadline    time.Time       `json:"deadline"`     // 该成员的截止时间（含延期）
+	Extended    bool            `json:"extended"`     // 是否获得延期
+	Cells       []GradebookCell `json:"cells"`        // 各题成绩，顺序与 problems 一致
+	TotalScore  int             `json:"total_score"`  // 总分
+	SolvedCount int             `json:"solved_count"` // 通过题数
+}
+
+// AssignmentGradebookResponse 作业成绩表响应
+type AssignmentGradebookResponse struct {
+	Assignment TeamAssignment     `json:"assignment"`
+	Problems   []GradebookProblem `json:"problems"`
+	Rows       []GradebookRow     `json:"rows"`
+	FullScore  int                `json:"full_score"` // 满分
+}
+
+// CloneAssignmentRequest 复制作业请求，所有时间按新的开始时间整体平移
+type CloneAssignmentRequest struct {
+	TargetTeamID uint64     `json:"target_team_id"`                // 目标团队ID，为空表示复制到原团队
+	Title        string     `json:"title"`                         // 新作业标题，为空表示沿用原标题
+	StartTime    time.Time  `json:"start_time" binding:"required"` // 新的开始时间
+	Hidden       bool       `json:"hidden"`                        // 是否创建为隐藏作业
+	PublishAt    *time.Time `json:"publish_at"`                    // 定时发布时间
+}
+
+// SaveAssignmentTemplateRequest 将作业保存为模板请求
+type SaveAssignmentTemplateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// CreateAssignmentFromTemplateRequest 从模板创建作业请求
+type CreateAssignmentFromTemplateRequest struct {
+	TemplateID uint64     `json:"template_id" binding:"required"`
+	TeamID     uint64     `json:"team_id" binding:"required"`
+	Title      string     `json:"title"`                         // 作业标题，为空表示沿用模板标题
+	StartTime  time.Time  `json:"start_time" binding:"required"` // 开始时间
+	Hidden     bool       `json:"hidden"`                        // 是否创建为隐藏作业
+	PublishAt  *time.Time `json:"publish_at"`                    // 定时发布时间
+}
+
+// GrantAssignmentExtensionRequest 为成员延期作业截止时间请求
+type GrantAssignmentExtensionRequest struct {
+	UserID  uint64    `json:"user_id" binding:"required"`
+	EndTime time.Time `json:"end_time" binding:"required"` // 延期后的截止时间
+	Reason  string    `json:"reason" binding:"max=255"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// 团队动态事件类型
+const (
+	TeamEventMemberJoined        = "member_joined"        // 成员加入
+	TeamEventMemberRemoved       = "member_removed"       // 成员被移除
+	TeamEventAssignmentPublished = "assignment_published" // 作业发布
+	TeamEventSubmission          = "submission"           // 作业提交
+	TeamEventAccepted            = "accepted"             // 作业题目首次通过
+	TeamEventProblemListCreated  = "problem_list_created" // 创建题单
+	TeamEventProblemListUpdated  = "problem_list_updated" // 更新题单
+)
+
+// TeamEvent 团队动态
+type TeamEvent struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	TeamID    uint64    `json:"team_id"`
+	ActorID   *uint64   `json:"actor_id"`
+	Type      string    `json:"type"`
+	TargetID  *uint64   `json:"target_id"`
+	Summary   string    `json:"summary"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeamEventInfo 团队动态信息
+type TeamEventInfo struct {
+	TeamEvent
+	ActorName string `json:"actor_name"`
+}
+
+// TeamEventListRequest 团队动态列表请求
+type TeamEventListRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Type     string `form:"type"` // 可选，按事件类型筛选
+}
+
+// TeamEventListResponse 团队动态列表响应
+type TeamEventListResponse struct {
+	Events   []TeamEventInfo `json:"events"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// TeamStatsRequest 团队统计请求
+type TeamStatsRequest struct {
+	Weeks int `form:"weeks" binding:"omitempty,min=1,max=52"` // 统计最近多少周，默认 8 周
+}
+
+// TeamWeeklyStats 团队每周统计，周一为一周的开始
+type TeamWeeklyStats struct {
+	WeekStart     time.Time `json:"week_start"`
+	ActiveMembers int       `json:"active_members"` // 本周有提交的成员数
+	Submissions   int       `json:"submissions"`
+	Accepted      int       `json:"accepted"`
+}
+
+// TeamAssignmentStats 作业统计
+type TeamAssignmentStats struct {
+	AssignmentID uint64  `json:"assignment_id"`
+	Title        string  `json:"title"`
+	Participants int     `json:"participants"` // 有提交的成员数
+	Submissions  int     `json:"submissions"`
+	Accepted     int     `json:"accepted"`
+	ACRate       float64 `json:"ac_rate"` // 通过率（百分比）
+}
+
+// TeamTagStats 标签统计
+type TeamTagStats struct {
+	TagID       uint64  `json:"tag_id"`
+	Name        string  `json:"name"`
+	Submissions int     `json:"submissions"`
+	Accepted    int     `json:"accepted"`
+	ACRate      float64 `json:"ac_rate"` // 通过率（百分比）
+}
+
+// TeamStatsResponse 团队统计面板
+type TeamStatsResponse struct {
+	MemberCount int                   `json:"member_count"`
+	Weeks       []TeamWeeklyStats     `json:"weeks"`
+	Assignments []TeamAssignmentStats `json:"assignments"`
+	WeakestTags []TeamTagStats        `json:"weakest_tags"` // 通过率最低的标签
+}
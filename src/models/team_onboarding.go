@@ -0,0 +1,42 @@
+package models
+
+// 批量导入结果状态
+const (
+	OnboardStatusCreated       = "created"        // 新建账号并加入团队
+	OnboardStatusJoined        = "joined"         // 已有账号，加入团队
+	OnboardStatusAlreadyMember = "already_member" // 已是团队成员
+	OnboardStatusFailed        = "failed"         // 导入失败
+)
+
+// OnboardMemberRow 批量导入的一行数据，CSV 列顺序：用户名、真实姓名、邮箱、学号、团队内名称（可选）
+type OnboardMemberRow struct {
+	Line      int    `json:"line"` // CSV 中的行号
+	Username  string `json:"username"`
+	RealName  string `json:"real_name"`
+	Email     string `json:"email"`
+	StudentID string `json:"student_id"`
+	Nickname  string `json:"nickname"`
+}
+
+// BulkOnboardRequest 批量导入团队成员请求（multipart 表单，CSV 文件字段为 file）
+type BulkOnboardRequest struct {
+	Role   string `form:"role"`                                      // 导入后的团队角色，默认为成员
+	Format string `form:"format" binding:"omitempty,oneof=json csv"` // 返回格式：json 或 csv 凭据文件
+}
+
+// OnboardMemberResult 单个成员的导入结果
+type OnboardMemberResult struct {
+	OnboardMemberRow
+	UserID   uint64 `json:"user_id,omitempty"`
+	Password string `json:"password,omitempty"` // 仅新建账号返回初始密码
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BulkOnboardResponse 批量导入团队成员响应
+type BulkOnboardResponse struct {
+	Results []OnboardMemberResult `json:"results"`
+	Created int                   `json:"created"`
+	Joined  int                   `json:"joined"`
+	Failed  int                   `json:"failed"`
+}
@@ -17,6 +17,8 @@ type TeamProblem struct {
 	CreatedBy         uint64    `json:"created_by"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
+
+	CopiedFrom *uint64 `json:"copied_from"` // 复制来源的团队题目
 }
 
 // TeamProblemTestCase 团队私有题目测试用例
@@ -85,3 +87,92 @@ type TeamProblemListResponse struct {
 	Page     int           `json:"page"`
 	PageSize int           `json:"page_size"`
 }
+
+// 团队题目共享方式
+const (
+	TeamProblemShareReadonly = "readonly" // 只读
+	TeamProblemShareCopy     = "copy"     // 允许复制
+)
+
+// TeamProblemShare 团队私有题目共享记录
+type TeamProblemShare struct {
+	ProblemID uint64    `json:"problem_id" gorm:"primaryKey"`
+	TeamID    uint64    `json:"team_id" gorm:"primaryKey"`
+	Mode      string    `json:"mode"`
+	SharedBy  uint64    `json:"shared_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeamProblemShareInfo 共享记录及团队名称
+type TeamProblemShareInfo struct {
+	TeamProblemShare
+	TeamName string `json:"team_name"`
+}
+
+// SharedTeamProblemInfo 共享给团队的题目
+type SharedTeamProblemInfo struct {
+	TeamProblem
+	Mode         string `json:"mode"`
+	SourceTeam   string `json:"source_team"`
+	SharedByName string `json:"shared_by_name"`
+}
+
+// ShareTeamProblemRequest 共享团队私有题目请求
+type ShareTeamProblemRequest struct {
+	TeamID uint64 `json:"team_id" binding:"required"`
+	Mode   string `json:"mode" binding:"omitempty,oneof=readonly copy"`
+}
+
+// CopyTeamProblemRequest 复制共享题目到本团队请求
+type CopyTeamProblemRequest struct {
+	TeamID uint64 `json:"team_id" binding:"required"`
+}
+
+// 推荐到公共题库的申请状态
+const (
+	ProblemPromotionPending  = "pending"  // 待审核
+	ProblemPromotionApproved = "approved" // 已通过
+	ProblemPromotionRejected = "rejected" // 已拒绝
+)
+
+// TeamProblemPromotion 团队私有题目推荐到公共题库的申请
+type TeamProblemPromotion struct {
+	ID            uint64     `json:"id"`
+	TeamProblemID uint64     `json:"team_problem_id"`
+	TeamID        uint64     `json:"team_id"`
+	RequestedBy   uint64     `json:"requested_by"`
+	Note          string     `json:"note"`
+	Status        string     `json:"status"`
+	ReviewedBy    *uint64    `json:"reviewed_by"`
+	ReviewNote    string     `json:"review_note"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ProblemID     *uint64    `json:"problem_id"` // 审核通过后生成的公共题目
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TeamProblemPromotionInfo 推荐申请列表项
+type TeamProblemPromotionInfo struct {
+	TeamProblemPromotion
+	Title         string `json:"title"`
+	TeamName      string `json:"team_name"`
+	RequesterName string `json:"requester_name"`
+}
+
+// NominateTeamProblemRequest 推荐题目到公共题库请求
+type NominateTeamProblemRequest struct {
+	Note string `json:"note"`
+}
+
+// ProblemPromotionListRequest 推荐申请列表请求
+type ProblemPromotionListRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+}
+
+// ReviewProblemPromotionRequest 审核推荐申请请求
+type ReviewProblemPromotionRequest struct {
+	Approve    *bool  `json:"approve" binding:"required"`
+	ReviewNote string `json:"review_note"`
+	Difficulty string `json:"difficulty"` // 通过时设置的难度等级，默认为暂无评级
+	IsPublic   bool   `json:"is_public"`  // 通过后是否立即公开
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// 团队内置角色
+const (
+	TeamRoleOwner     = "owner"     // 所有者
+	TeamRoleAdmin     = "admin"     // 管理员
+	TeamRoleAssistant = "assistant" // 助教
+	TeamRoleMember    = "member"    // 成员
+	TeamRoleObserver  = "observer"  // 旁听
+
+	// TeamRoleOrgAdmin 团队所属组织（含上级组织）的管理员，不是团队成员但拥有全部权限
+	TeamRoleOrgAdmin = "org_admin"
+)
+
+// 团队权限
+const (
+	TeamPermManageTeam       = "manage_team"       // 修改团队信息和头像
+	TeamPermManageMembers    = "manage_members"    // 邀请、审核申请、移除成员和分配角色
+	TeamPermCreateAssignment = "create_assignment" // 创建、编辑、复制和发布作业，管理模板与延期
+	TeamPermManageProblems   = "manage_problems"   // 管理团队私有题目和题单
+	TeamPermViewSubmissions  = "view_submissions"  // 查看全部提交记录和成绩表
+	TeamPermGrade            = "grade"             // 批阅作业、人工评分
+	TeamPermSubmitAssignment = "submit_assignment" // 提交作业
+)
+
+// AllTeamPermissions 全部团队权限
+var AllTeamPermissions = []string{
+	TeamPermManageTeam,
+	TeamPermManageMembers,
+	TeamPermCreateAssignment,
+	TeamPermManageProblems,
+	TeamPermViewSubmissions,
+	TeamPermGrade,
+	TeamPermSubmitAssignment,
+}
+
+// DefaultTeamRoles 内置角色及默认权限，团队可以覆盖除所有者外的内置角色权限
+var DefaultTeamRoles = []TeamRoleInfo{
+	{Role: TeamRoleOwner, Name: "所有者", BuiltIn: true, Permissions: AllTeamPermissions},
+	{Role: TeamRoleAdmin, Name: "管理员", BuiltIn: true, Permissions: []string{
+		TeamPermManageTeam, TeamPermManageMembers, TeamPermCreateAssignment,
+		TeamPermManageProblems, TeamPermViewSubmissions, TeamPermGrade, TeamPermSubmitAssignment,
+	}},
+	{Role: TeamRoleAssistant, Name: "助教", BuiltIn: true, Permissions: []string{
+		TeamPermViewSubmissions, TeamPermGrade,
+	}},
+	{Role: TeamRoleMember, Name: "成员", BuiltIn: true, Permissions: []string{
+		TeamPermSubmitAssignment,
+	}},
+	{Role: TeamRoleObserver, Name: "旁听", BuiltIn: true, Permissions: []string{}},
+}
+
+// TeamRole 团队自定义角色，或对内置角色权限的覆盖
+type TeamRole struct {
+	TeamID      uint64    `json:"team_id" gorm:"primaryKey"`
+	Role        string    `json:"role" gorm:"primaryKey"`
+	Name        string    `json:"name"`
+	Permissions string    `json:"permissions"` // 逗号分隔的权限列表
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TeamRoleInfo 团队角色信息
+type TeamRoleInfo struct {
+	Role        string   `json:"role"`
+	Name        string   `json:"name"`
+	BuiltIn     bool     `json:"built_in"` // 是否为内置角色
+	Permissions []string `json:"permissions"`
+}
+
+// SaveTeamRoleRequest 创建或更新团队角色请求
+type SaveTeamRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Permissions []string `json:"permissions" binding:"dive,oneof=manage_team manage_members create_assignment manage_problems view_submissions grade submit_assignment"`
+}
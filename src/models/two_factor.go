@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// UserTwoFactor 用户的 TOTP 两步验证配置，Enabled 为 false 时表示已生成密钥但尚未完成绑定
+type UserTwoFactor struct {
+	UserID    uint64     `json:"user_id" gorm:"primaryKey"`
+	Secret    string     `json:"-"` // base32 编码的 TOTP 密钥
+	Enabled   bool       `json:"enabled"`
+	EnabledAt *time.Time `json:"enabled_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// UserRecoveryCode 两步验证恢复码，每个恢复码只能使用一次
+type UserRecoveryCode struct {
+	ID        uint64     `json:"id"`
+	UserID    uint64     `json:"user_id"`
+	CodeHash  string     `json:"-"` // 恢复码的 SHA-256 摘要
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"` // 管理员和持有网站权限的账号必须启用两步验证
+	EnabledAt         *time.Time `json:"enabled_at"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"` // 剩余可用的恢复码数量
+}
+
+// TwoFactorSetupResponse 开始绑定两步验证的响应，客户端可将 otpauth_url 渲染为二维码
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+}
+
+// TwoFactorCodeRequest 提交两步验证码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest 关闭两步验证请求
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 验证码或恢复码
+}
+
+// TwoFactorChallenge 登录第一步通过后返回的两步验证挑战
+type TwoFactorChallenge struct {
+	Token         string `json:"challenge_token"`
+	SetupRequired bool   `json:"two_factor_setup_required"` // 账号必须启用但尚未绑定两步验证
+}
+
+// TwoFactorChallengeRequest 使用登录挑战绑定两步验证
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorLoginRequest 登录第二步：提交验证码或恢复码
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	CaptchaID      string `json:"captchaID"` // 连续验证失败后需要先完成人机验证
+}
+
+// RecoveryCodesResponse 新生成的恢复码，只在生成时返回一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -8,6 +8,8 @@ type User struct {
 	Password string  `json:"-"`
 	Phone    *string `json:"phone,omitempty" gorm:"uniqueIndex"`
 	Email    string  `json:"email,omitempty"`
+
+	MustChangePassword bool `json:"must_change_password"` // 首次登录需修改密码
 }
 
 type RegisterRequest struct {
@@ -24,6 +26,7 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	AccountInfo string `json:"accountInfo"`
 	PassWord    string `json:"passWord"`
+	CaptchaID   string `json:"captchaID"` // 连续登录失败后需要先完成人机验证
 }
 
 // UserListRequest 用户列表请求
@@ -85,3 +88,24 @@ type GenerateUsersResponse struct {
 	Users []GeneratedUserInfo `json:"users"`
 	Total int                 `json:"total"`
 }
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ForgotPasswordRequest 找回密码请求，向账号绑定的邮箱或手机号发送重置验证码
+type ForgotPasswordRequest struct {
+	AccountType string `json:"account_type" binding:"required,oneof=email phone"`
+	Account     string `json:"account" binding:"required"`
+	CaptchaID   string `json:"captcha_id" binding:"required"`
+}
+
+// ResetPasswordRequest 使用重置验证码设置新密码
+type ResetPasswordRequest struct {
+	AccountType string `json:"account_type" binding:"required,oneof=email phone"`
+	Account     string `json:"account" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
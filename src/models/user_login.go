@@ -24,6 +24,16 @@ type LoginHistoryRequest struct {
 	StartTime time.Time `form:"start_time"`
 	EndTime   time.Time `form:"end_time"`
 	Status    string    `form:"status"`
+	IPAddress string    `form:"ip_address"`
 	Page      int       `form:"page" binding:"required,min=1"`
 	PageSize  int       `form:"page_size" binding:"required,min=1,max=100"`
 }
+
+// LoginIPSummary 用户登录IP汇总
+type LoginIPSummary struct {
+	IPAddress    string    `json:"ip_address"`
+	LoginCount   int64     `json:"login_count"`
+	FailedCount  int64     `json:"failed_count"`
+	FirstLoginAt time.Time `json:"first_login_at"`
+	LastLoginAt  time.Time `json:"last_login_at"`
+}
@@ -2,44 +2,115 @@ package routes
 
 import (
 	"OptiOJ/src/controllers"
+	"OptiOJ/src/middleware"
+	"OptiOJ/src/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRoutes(r *gin.Engine) {
+	// 公开路由：无需登录，携带令牌时解析当前用户
+	public := r.Group("", middleware.OptionalAuth())
+	// 登录用户路由
+	authorized := r.Group("", middleware.RequireAuth())
+	// 管理后台路由：需要登录，具体权限由各路由单独声明
+	admin := r.Group("", middleware.RequireAuth())
+	// 超级管理员路由
+	superAdmin := r.Group("", middleware.RequireAuth(), middleware.RequireSuperAdmin())
+
+	setupPublicRoutes(public)
+	setupAuthorizedRoutes(authorized)
+	setupAdminRoutes(admin)
+	setupSuperAdminRoutes(superAdmin)
+
+	// 首次登录需修改密码的用户也可以调用
+	r.PUT("/user/changePassword", middleware.RequirePasswordChangeAuth(), controllers.ChangePassword)
+}
+
+// setupPublicRoutes 公开路由
+func setupPublicRoutes(r *gin.RouterGroup) {
 	r.POST("/auth/userRegister", controllers.RegisterUser)
 	r.POST("/auth/userLogin", controllers.LoginUser)
-	r.POST("/user/uploadAvatar", controllers.UploadAvatar)
-	r.PUT("/user/updateProfile", controllers.UpdateProfile)
+	r.POST("/auth/twoFactorLogin", controllers.TwoFactorLogin)             // 登录第二步：提交两步验证码
+	r.POST("/auth/twoFactor/setup", controllers.SetupTwoFactorByChallenge) // 登录过程中绑定两步验证（管理员和持有网站权限的账号必须启用）
+	r.POST("/auth/forgotPassword", controllers.ForgotPassword)             // 找回密码：发送重置验证码
+	r.POST("/auth/resetPassword", controllers.ResetPassword)               // 使用重置验证码设置新密码
+	r.GET("/auth/oidc/provider", controllers.GetOIDCProvider)              // 获取单点登录配置
+	r.GET("/auth/oidc/authorize", controllers.GetOIDCAuthorizationURL)     // 获取身份提供方授权地址
+	r.POST("/auth/oidc/login", controllers.OIDCLogin)                      // 使用授权码完成单点登录
 	r.GET("/auth/refreshToken", controllers.RefreshToken)
-	r.GET("/user/globalData", controllers.GetGlobalData)
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS) // 获取令牌验证公钥
 	r.GET("/user/getAvatar", controllers.GetAvatar)
 	r.GET("/user/getProvinces", controllers.GetProvinces)
 	r.GET("/user/getCities", controllers.GetCities)
-	r.DELETE("/user/removeAvatar", controllers.RemoveAvatar)
 	r.POST("/verification/sendVerificationCode", controllers.RequestVerification)
 	r.POST("/verification/validateCaptcha", controllers.ValidateGeetest)
 
+	r.POST("/sessions/logoutSession", controllers.Logout) // 退出当前设备（使用刷新令牌）
+
+	r.GET("/user/:id/activity", controllers.GetUserActivity) // 获取用户活跃度
+
+	// 题目相关路由
+	problems := r.Group("/problems")
+	{
+		problems.GET("/:id", controllers.GetProblemDetail)                  // 获取题目详情
+		problems.GET("", controllers.GetProblemList)                        // 获取题目列表
+		problems.GET("/difficulty-system", controllers.GetDifficultySystem) // 获取难度等级系统
+	}
+
+	// 标签相关路由
+	tags := r.Group("/tags")
+	{
+		tags.GET("/getTagList", controllers.GetTagList)                            // 获取标签列表
+		tags.GET("/categories/getTagCategoryList", controllers.GetTagCategoryList) // 获取标签分类列表
+		tags.GET("/categories/getTagCategoryTree", controllers.GetTagCategoryTree) // 获取标签分类树形结构
+	}
+
+	// 团队相关路由
+	teams := r.Group("/teams")
+	{
+		teams.GET("/:id/getTeamDetail", controllers.GetTeamDetail)        // 获取团队详情
+		teams.GET("/getTeamList", controllers.GetTeamList)                // 获取团队列表
+		teams.GET("/avatar/:filename", controllers.GetTeamAvatar)         // 获取团队头像
+		teams.GET("/problem-lists/:id", controllers.GetProblemListDetail) // 获取题单详情
+		teams.GET("/problem-lists", controllers.GetProblemListList)       // 获取题单列表
+	}
+}
+
+// setupAuthorizedRoutes 登录用户路由
+func setupAuthorizedRoutes(r *gin.RouterGroup) {
+	r.POST("/user/uploadAvatar", controllers.UploadAvatar)
+	r.PUT("/user/updateProfile", controllers.UpdateProfile)
+	r.GET("/user/globalData", controllers.GetGlobalData)
+	r.DELETE("/user/removeAvatar", controllers.RemoveAvatar)
+
 	// 会话管理相关路由
 	sessions := r.Group("/sessions")
 	{
 		sessions.GET("/activeSessions", controllers.GetActiveSessions)           // 获取活跃会话列表
-		sessions.POST("/logoutSession", controllers.Logout)                      // 退出当前设备
 		sessions.POST("/logoutAllSessions", controllers.LogoutAllDevices)        // 退出所有设备
 		sessions.DELETE("/logoutSession/:session_id", controllers.RevokeSession) // 吊销指定会话
 	}
 
-	r.POST("/admin/addAdmin", controllers.AddAdmin)
-	r.DELETE("/admin/removeAdmin", controllers.RemoveAdmin)
-	r.GET("/admin/listAdmin", controllers.GetAdminList)
+	r.GET("/user/loginHistory", controllers.GetLoginHistory) // 获取登录历史（管理员可按用户筛选）
 
-	r.GET("/admin/users", controllers.GetUserList)
-	r.PUT("/admin/users/:id", controllers.UpdateUser)
-	r.POST("/admin/users/:id/ban", controllers.BanUser)
-	r.POST("/admin/users/:id/unban", controllers.UnbanUser)
-	r.POST("/admin/users/generateUser", controllers.GenerateUsers)
+	// 两步验证相关路由
+	twoFactor := r.Group("/user/twoFactor")
+	{
+		twoFactor.GET("", controllers.GetTwoFactorStatus)                     // 获取两步验证状态
+		twoFactor.POST("/setup", controllers.SetupTwoFactor)                  // 生成密钥和二维码链接
+		twoFactor.POST("/enable", controllers.EnableTwoFactor)                // 提交验证码启用两步验证
+		twoFactor.POST("/disable", controllers.DisableTwoFactor)              // 关闭两步验证
+		twoFactor.POST("/recoveryCodes", controllers.RegenerateRecoveryCodes) // 重新生成恢复码
+	}
 
-	r.GET("/user/:id/activity", controllers.GetUserActivity) // 获取用户活跃度
+	// 个人访问令牌相关路由
+	tokens := r.Group("/user/tokens")
+	{
+		tokens.GET("", controllers.GetPersonalAccessTokens)          // 获取个人访问令牌列表
+		tokens.POST("", controllers.CreatePersonalAccessToken)       // 创建个人访问令牌
+		tokens.DELETE("/:id", controllers.RevokePersonalAccessToken) // 吊销个人访问令牌
+	}
 
 	// 站内信相关路由
 	messages := r.Group("/messages")
@@ -52,53 +123,6 @@ func SetupRoutes(r *gin.Engine) {
 		messages.GET("/getUnreadCount", controllers.GetUnreadMessageCount) // 获取未读消息数量
 	}
 
-	// 题目管理相关路由
-	problems := r.Group("/problems")
-	{
-		problems.POST("", controllers.CreateProblem)                                   // 创建题目                          // 更新题目
-		problems.DELETE("/:id", controllers.DeleteProblem)                             // 删除题目
-		problems.GET("/:id", controllers.GetProblemDetail)                             // 获取题目详情
-		problems.GET("", controllers.GetProblemList)                                   // 获取题目列表
-		problems.POST("/switch-difficulty-system", controllers.SwitchDifficultySystem) // 切换难度等级系统
-		problems.GET("/difficulty-system", controllers.GetDifficultySystem)            // 获取难度等级系统
-	}
-
-	// 管理员专用的题目管理路由
-	adminProblems := r.Group("/admin/problems")
-	{
-		adminProblems.GET("", controllers.AdminGetProblemList)       // 管理员获取题目列表
-		adminProblems.GET("/:id", controllers.AdminGetProblemDetail) // 管理员获取题目详情
-		adminProblems.PUT("/:id", controllers.AdminUpdateProblem)    // 管理员更新题目
-	}
-
-	// 标签管理相关路由
-	tags := r.Group("/tags")
-	{
-		tags.POST("", controllers.CreateTag)            // 创建标签
-		tags.PUT("/:id", controllers.UpdateTag)         // 更新标签
-		tags.DELETE("/:id", controllers.DeleteTag)      // 删除标签
-		tags.GET("/getTagList", controllers.GetTagList) // 获取标签列表
-
-		// 标签分类相关路由
-		categories := tags.Group("/categories")
-		{
-			categories.POST("/createTagCategory", controllers.CreateTagCategory)       // 创建标签分类
-			categories.PUT("/:id/updateTagCategory", controllers.UpdateTagCategory)    // 更新标签分类
-			categories.DELETE("/:id/deleteTagCategory", controllers.DeleteTagCategory) // 删除标签分类
-			categories.GET("/getTagCategoryList", controllers.GetTagCategoryList)      // 获取标签分类列表
-			categories.GET("/getTagCategoryTree", controllers.GetTagCategoryTree)      // 获取标签分类树形结构
-		}
-	}
-
-	// 测试用例管理相关路由
-	testcases := r.Group("/testcases")
-	{
-		testcases.POST("", controllers.UploadTestCase)                  // 上传测试用例
-		testcases.DELETE("/:id", controllers.DeleteTestCase)            // 删除测试用例
-		testcases.GET("/problem/:problem_id", controllers.GetTestCases) // 获取题目的测试用例列表
-		testcases.GET("/:id/content", controllers.GetTestCaseContent)   // 获取测试用例内容
-	}
-
 	// 判题相关路由
 	submissions := r.Group("/submissions")
 	{
@@ -108,24 +132,53 @@ func SetupRoutes(r *gin.Engine) {
 		submissions.POST("/debug", controllers.Debug)            // 在线调试代码
 	}
 
+	// 组织相关路由
+	orgs := r.Group("/orgs")
+	{
+		orgs.POST("/createOrg", controllers.CreateOrganization)                         // 创建组织
+		orgs.PUT("/:id/updateOrg", controllers.UpdateOrganization)                      // 更新组织信息
+		orgs.DELETE("/:id/deleteOrg", controllers.DeleteOrganization)                   // 删除组织
+		orgs.GET("/getOrgTree", controllers.GetOrganizationTree)                        // 获取组织树
+		orgs.GET("/:id/getAdmins", controllers.GetOrganizationAdmins)                   // 获取组织管理员列表
+		orgs.POST("/:id/addAdmin", controllers.AddOrganizationAdmin)                    // 添加组织管理员
+		orgs.DELETE("/:id/admins/:user_id", controllers.RemoveOrganizationAdmin)        // 移除组织管理员
+		orgs.GET("/:id/getProblems", controllers.GetOrganizationProblems)               // 获取组织题库
+		orgs.POST("/:id/addProblem", controllers.AddOrganizationProblem)                // 添加组织题库题目
+		orgs.DELETE("/:id/problems/:problem_id", controllers.RemoveOrganizationProblem) // 移除组织题库题目
+		orgs.GET("/:id/getSchoolMembers", controllers.GetSchoolMembers)                 // 获取关联学校的用户
+		orgs.POST("/:id/verifyMember/:user_id", controllers.VerifySchoolMember)         // 认证用户的学校关联
+	}
+
 	// 团队相关路由
 	teams := r.Group("/teams")
 	{
-		teams.POST("/createTeam", controllers.CreateTeam)                     // 创建团队
-		teams.PUT("/:id/updateTeam", controllers.UpdateTeam)                  // 更新团队信息
-		teams.DELETE("/:id/deleteTeam", controllers.DeleteTeam)               // 删除团队
-		teams.GET("/:id/getTeamDetail", controllers.GetTeamDetail)            // 获取团队详情
-		teams.GET("/getTeamList", controllers.GetTeamList)                    // 获取团队列表
-		teams.POST("/:id/createInvitation", controllers.CreateTeamInvitation) // 创建团队邀请
-		teams.POST("/join", controllers.JoinTeam)                             // 加入团队
-		teams.PUT("/:id/members/role", controllers.UpdateTeamMemberRole)      // 更新成员角色
-		teams.DELETE("/:id/members/:user_id", controllers.RemoveTeamMember)   // 移除成员
-		teams.GET("/:id/getMembers", controllers.GetTeamMemberList)           // 获取成员列表
-		teams.PUT("/:id/changeNickname", controllers.UpdateTeamNickname)      // 更新团队内名称
+		teams.POST("/createTeam", controllers.CreateTeam)                                 // 创建团队
+		teams.PUT("/:id/updateTeam", controllers.UpdateTeam)                              // 更新团队信息
+		teams.DELETE("/:id/deleteTeam", controllers.DeleteTeam)                           // 删除团队（归档）
+		teams.POST("/:id/archive", controllers.ArchiveTeam)                               // 归档团队
+		teams.POST("/:id/restore", controllers.RestoreTeam)                               // 恢复团队
+		teams.POST("/:id/transferOwnership", controllers.TransferTeamOwnership)           // 发起所有权转让
+		teams.POST("/:id/cancelTransfer", controllers.CancelOwnershipTransfer)            // 取消所有权转让
+		teams.GET("/transfers/getPending", controllers.GetPendingOwnershipTransfers)      // 获取待确认的所有权转让
+		teams.POST("/transfers/:id/respond", controllers.RespondOwnershipTransfer)        // 确认或拒绝所有权转让
+		teams.POST("/:id/createInvitation", controllers.CreateTeamInvitation)             // 创建团队邀请
+		teams.GET("/:id/getInvitations", controllers.GetTeamInvitations)                  // 获取团队邀请列表
+		teams.DELETE("/:id/invitations/:invitation_id", controllers.RevokeTeamInvitation) // 撤销团队邀请
+		teams.POST("/join", controllers.JoinTeam)                                         // 加入团队
+		teams.PUT("/:id/members/role", controllers.UpdateTeamMemberRole)                  // 更新成员角色
+		teams.DELETE("/:id/members/:user_id", controllers.RemoveTeamMember)               // 移除成员
+		teams.POST("/:id/bulkOnboard", controllers.BulkOnboardTeamMembers)                // 批量导入成员并生成账号
+		teams.GET("/:id/getMembers", controllers.GetTeamMemberList)                       // 获取成员列表
+		teams.PUT("/:id/changeNickname", controllers.UpdateTeamNickname)                  // 更新团队内名称
+		teams.GET("/:id/getRoles", controllers.GetTeamRoles)                              // 获取团队角色列表
+		teams.PUT("/:id/roles/:role", controllers.SaveTeamRole)                           // 创建或修改团队角色
+		teams.DELETE("/:id/roles/:role", controllers.DeleteTeamRole)                      // 删除团队角色
+		teams.PUT("/:id/setOrg", controllers.SetTeamOrganization)                         // 设置团队所属组织
+		teams.GET("/:id/activity", controllers.GetTeamEvents)                             // 获取团队动态
+		teams.GET("/:id/stats", controllers.GetTeamStats)                                 // 获取团队统计面板
 
 		// 团队头像相关路由
 		teams.POST("/:id/avatar", controllers.UploadTeamAvatar)   // 上传团队头像
-		teams.GET("/avatar/:filename", controllers.GetTeamAvatar) // 获取团队头像
 		teams.DELETE("/:id/avatar", controllers.RemoveTeamAvatar) // 删除团队头像
 
 		// 团队申请相关路由
@@ -136,34 +189,129 @@ func SetupRoutes(r *gin.Engine) {
 		// 团队作业相关路由
 		assignments := teams.Group("/assignments")
 		{
-			assignments.POST("/createAssignment", controllers.CreateAssignment)           // 创建作业
-			assignments.PUT("/:id/updateAssignment", controllers.UpdateAssignment)        // 更新作业
-			assignments.GET("/:id/getAssignmentDetail", controllers.GetAssignmentDetail)  // 获取作业详情
-			assignments.GET("/getAssignmentList", controllers.GetAssignmentList)          // 获取作业列表
-			assignments.GET("/getAvailableProblems", controllers.GetAvailableProblemList) // 获取可用题目列表
-			assignments.GET("/getAssignmentProblems", controllers.GetAssignmentProblems)  // 获取作业题目列表
-			assignments.GET("/getProblemDetail", controllers.GetAssignmentProblemDetail)  // 获取作业题目详情
-			assignments.POST("/submitCode", controllers.SubmitAssignmentCode)             // 提交作业代码
-			assignments.GET("/getSubmissions", controllers.GetAssignmentSubmissions)      // 获取作业提交记录
+			assignments.POST("/createAssignment", controllers.CreateAssignment)                                   // 创建作业
+			assignments.PUT("/:id/updateAssignment", controllers.UpdateAssignment)                                // 更新作业
+			assignments.GET("/:id/getAssignmentDetail", controllers.GetAssignmentDetail)                          // 获取作业详情
+			assignments.GET("/getAssignmentList", controllers.GetAssignmentList)                                  // 获取作业列表
+			assignments.GET("/getAvailableProblems", controllers.GetAvailableProblemList)                         // 获取可用题目列表
+			assignments.GET("/getAssignmentProblems", controllers.GetAssignmentProblems)                          // 获取作业题目列表
+			assignments.GET("/getProblemDetail", controllers.GetAssignmentProblemDetail)                          // 获取作业题目详情
+			assignments.POST("/submitCode", controllers.SubmitAssignmentCode)                                     // 提交作业代码
+			assignments.GET("/getSubmissions", controllers.GetAssignmentSubmissions)                              // 获取作业提交记录
+			assignments.GET("/getGradebook", controllers.GetAssignmentGradebook)                                  // 获取作业成绩表
+			assignments.GET("/exportGradebook", controllers.ExportAssignmentGradebook)                            // 导出作业成绩表
+			assignments.POST("/:id/grantExtension", controllers.GrantAssignmentExtension)                         // 为成员延期作业
+			assignments.DELETE("/:id/revokeExtension/:user_id", controllers.RevokeAssignmentExtension)            // 撤销成员延期
+			assignments.GET("/:id/getExtensions", controllers.GetAssignmentExtensions)                            // 获取作业延期列表
+			assignments.POST("/submissions/:id/addComment", controllers.AddSubmissionComment)                     // 添加代码行评论
+			assignments.DELETE("/submissions/:id/deleteComment/:comment_id", controllers.DeleteSubmissionComment) // 删除代码行评论
+			assignments.POST("/submissions/:id/grade", controllers.GradeSubmission)                               // 人工评分
+			assignments.POST("/:id/clone", controllers.CloneAssignment)                                           // 复制作业
+			assignments.POST("/:id/publish", controllers.PublishAssignment)                                       // 立即发布作业
+			assignments.POST("/:id/saveAsTemplate", controllers.SaveAssignmentTemplate)                           // 将作业保存为模板
+			assignments.GET("/getTemplates", controllers.GetAssignmentTemplates)                                  // 获取作业模板列表
+			assignments.DELETE("/templates/:id/deleteTemplate", controllers.DeleteAssignmentTemplate)             // 删除作业模板
+			assignments.POST("/createFromTemplate", controllers.CreateAssignmentFromTemplate)                     // 从模板创建作业
 		}
 
 		// 团队私有题目相关路由
 		teamProblems := teams.Group("/problems")
 		{
-			teamProblems.POST("", controllers.CreateTeamProblem)       // 创建团队私有题目
-			teamProblems.PUT("/:id", controllers.UpdateTeamProblem)    // 更新团队私有题目
-			teamProblems.DELETE("/:id", controllers.DeleteTeamProblem) // 删除团队私有题目
-			teamProblems.GET("/:id", controllers.GetTeamProblemDetail) // 获取团队私有题目详情
-			teamProblems.GET("", controllers.GetTeamProblemList)       // 获取团队私有题目列表
+			teamProblems.POST("", controllers.CreateTeamProblem)                       // 创建团队私有题目
+			teamProblems.PUT("/:id", controllers.UpdateTeamProblem)                    // 更新团队私有题目
+			teamProblems.DELETE("/:id", controllers.DeleteTeamProblem)                 // 删除团队私有题目
+			teamProblems.GET("/:id", controllers.GetTeamProblemDetail)                 // 获取团队私有题目详情
+			teamProblems.GET("", controllers.GetTeamProblemList)                       // 获取团队私有题目列表
+			teamProblems.GET("/shared", controllers.GetSharedTeamProblems)             // 获取其他团队共享给本团队的题目
+			teamProblems.POST("/:id/share", controllers.ShareTeamProblem)              // 共享题目给其他团队
+			teamProblems.DELETE("/:id/share/:team_id", controllers.UnshareTeamProblem) // 取消对某个团队的共享
+			teamProblems.GET("/:id/shares", controllers.GetTeamProblemShares)          // 获取题目的共享记录
+			teamProblems.POST("/:id/copy", controllers.CopySharedTeamProblem)          // 复制共享的题目到本团队
+			teamProblems.POST("/:id/nominate", controllers.NominateTeamProblem)        // 推荐题目进入公共题库
 		}
 
 		// 团队题单相关路由
 		problemLists := teams.Group("/problem-lists")
 		{
-			problemLists.POST("", controllers.CreateProblemList)       // 创建题单
-			problemLists.PUT("/:id", controllers.UpdateProblemList)    // 更新题单
-			problemLists.GET("/:id", controllers.GetProblemListDetail) // 获取题单详情
-			problemLists.GET("", controllers.GetProblemListList)       // 获取题单列表
+			problemLists.POST("", controllers.CreateProblemList)                  // 创建题单
+			problemLists.PUT("/:id", controllers.UpdateProblemList)               // 更新题单
+			problemLists.GET("/:id/progress", controllers.GetProblemListProgress) // 获取题单成员进度和排行榜
 		}
 	}
 }
+
+// setupAdminRoutes 管理后台路由，每个路由声明所需的网站权限
+func setupAdminRoutes(r *gin.RouterGroup) {
+	perm := middleware.RequirePermission
+
+	r.GET("/admin/listAdmin", perm(models.SitePermAdminView), controllers.GetAdminList)
+
+	r.GET("/admin/users", perm(models.SitePermUserView), controllers.GetUserList)
+	r.PUT("/admin/users/:id", perm(models.SitePermUserEdit), controllers.UpdateUser)
+	r.POST("/admin/users/:id/ban", perm(models.SitePermUserBan), controllers.BanUser)
+	r.POST("/admin/users/:id/unban", perm(models.SitePermUserBan), controllers.UnbanUser)
+	r.POST("/admin/users/generateUser", perm(models.SitePermUserCreate), controllers.GenerateUsers)
+	r.GET("/admin/users/:id/loginIPs", perm(models.SitePermUserView), controllers.GetUserLoginIPs) // 查看用户登录IP汇总
+	r.GET("/admin/loginLocks", perm(models.SitePermUserView), controllers.GetLoginLocks)           // 查看被锁定的账号和IP
+	r.POST("/admin/loginLocks/unlock", perm(models.SitePermUserBan), controllers.UnlockLogin)      // 解除登录锁定
+
+	r.GET("/admin/problemPromotions", perm(models.SitePermProblemReview), controllers.GetProblemPromotions)               // 获取团队题目推荐申请列表
+	r.POST("/admin/problemPromotions/:id/review", perm(models.SitePermProblemReview), controllers.ReviewProblemPromotion) // 审核团队题目推荐申请
+
+	// 网站角色管理相关路由
+	roles := r.Group("/admin", perm(models.SitePermRoleManage))
+	{
+		roles.GET("/roles", controllers.GetSiteRoles)               // 获取网站角色列表
+		roles.PUT("/roles/:role", controllers.SaveSiteRole)         // 创建或修改网站角色
+		roles.DELETE("/roles/:role", controllers.DeleteSiteRole)    // 删除网站角色
+		roles.GET("/users/:id/roles", controllers.GetUserSiteRoles) // 获取用户的网站角色
+		roles.PUT("/users/:id/roles", controllers.SetUserSiteRoles) // 设置用户的网站角色
+	}
+
+	// 题目管理相关路由
+	problems := r.Group("/problems")
+	{
+		problems.POST("", perm(models.SitePermProblemCreate), controllers.CreateProblem)                                 // 创建题目
+		problems.DELETE("/:id", perm(models.SitePermProblemDelete), controllers.DeleteProblem)                           // 删除题目
+		problems.POST("/switch-difficulty-system", perm(models.SitePermProblemEdit), controllers.SwitchDifficultySystem) // 切换难度等级系统
+	}
+
+	// 管理员专用的题目管理路由
+	adminProblems := r.Group("/admin/problems")
+	{
+		adminProblems.GET("", perm(models.SitePermProblemRead), controllers.AdminGetProblemList)       // 管理员获取题目列表
+		adminProblems.GET("/:id", perm(models.SitePermProblemRead), controllers.AdminGetProblemDetail) // 管理员获取题目详情
+		adminProblems.PUT("/:id", perm(models.SitePermProblemEdit), controllers.AdminUpdateProblem)    // 管理员更新题目
+	}
+
+	// 标签管理相关路由
+	tags := r.Group("/tags", perm(models.SitePermTagManage))
+	{
+		tags.POST("", controllers.CreateTag)       // 创建标签
+		tags.PUT("/:id", controllers.UpdateTag)    // 更新标签
+		tags.DELETE("/:id", controllers.DeleteTag) // 删除标签
+
+		// 标签分类相关路由
+		categories := tags.Group("/categories")
+		{
+			categories.POST("/createTagCategory", controllers.CreateTagCategory)       // 创建标签分类
+			categories.PUT("/:id/updateTagCategory", controllers.UpdateTagCategory)    // 更新标签分类
+			categories.DELETE("/:id/deleteTagCategory", controllers.DeleteTagCategory) // 删除标签分类
+		}
+	}
+
+	// 测试用例管理相关路由
+	testcases := r.Group("/testcases")
+	{
+		testcases.POST("", perm(models.SitePermTestcaseWrite), controllers.UploadTestCase)                 // 上传测试用例
+		testcases.DELETE("/:id", perm(models.SitePermTestcaseWrite), controllers.DeleteTestCase)           // 删除测试用例
+		testcases.GET("/problem/:problem_id", perm(models.SitePermTestcaseRead), controllers.GetTestCases) // 获取题目的测试用例列表
+		testcases.GET("/:id/content", perm(models.SitePermTestcaseRead), controllers.GetTestCaseContent)   // 获取测试用例内容
+	}
+}
+
+// setupSuperAdminRoutes 超级管理员路由
+func setupSuperAdminRoutes(r *gin.RouterGroup) {
+	r.POST("/admin/addAdmin", controllers.AddAdmin)
+	r.DELETE("/admin/removeAdmin", controllers.RemoveAdmin)
+}
@@ -5,6 +5,8 @@ import (
 	"OptiOJ/src/models"
 	"errors"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // 检查用户是否为管理员
@@ -29,6 +31,19 @@ func IsSuperAdmin(userID uint) (bool, error) {
 	return count > 0, nil
 }
 
+// GetAdminRole 获取用户的管理员角色（super_admin 或 admin），非管理员返回空字符串
+func GetAdminRole(userID uint) (string, error) {
+	var admin models.Admin
+	err := config.DB.Select("role").Where("user_id = ?", userID).First(&admin).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return admin.Role, nil
+}
+
 // 添加管理员
 func AddAdmin(userID uint, role string) error {
 	// 验证角色类型
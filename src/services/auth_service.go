@@ -9,8 +9,12 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// ErrPasswordChangeRequired 首次登录需要先修改密码
+var ErrPasswordChangeRequired = errors.New("首次登录请先修改密码")
+
 type Claims struct {
 	UserID uint `json:"user_id"`
 	jwt.RegisteredClaims
@@ -22,6 +26,7 @@ func GenerateToken(userID uint, duration time.Duration) (string, error) {
 	claims := &Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(), // 保证同一秒内签发的令牌也互不相同
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -29,19 +34,35 @@ func GenerateToken(userID uint, duration time.Duration) (string, error) {
 		},
 	}
 
-	// 使用密钥创建token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// 使用当前签名密钥创建token，并在头部标明密钥ID
+	key := config.CurrentJWTKey()
+	if key == nil {
+		return "", errors.New("没有可用的JWT签名密钥")
+	}
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
 
 	// 签名并获得完整的编码后的字符串令牌
-	return token.SignedString(config.JWTSecret)
+	return token.SignedString(key.SigningKey())
+}
+
+// jwtKeyFunc 根据令牌头部的 kid 选择验证密钥，并校验签名算法与密钥一致
+func jwtKeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := config.FindJWTKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.SigningMethod().Alg() {
+		return nil, fmt.Errorf("意外的签名方法: %v", token.Header["alg"])
+	}
+	return key.VerifyKey(), nil
 }
 
 // ValidateRefreshToken 验证刷新令牌
 func ValidateRefreshToken(refreshToken string) (uint, error) {
 	// 解析令牌
-	token, err := jwt.ParseWithClaims(refreshToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return config.JWTSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(refreshToken, &Claims{}, jwtKeyFunc)
 
 	if err != nil {
 		return 0, err
@@ -100,20 +121,40 @@ func GenerateTokenPair(userID uint) (accessToken, refreshToken string, err error
 	return accessToken, refreshToken, nil
 }
 
-// ValidateAccessToken 验证访问令牌
+// ValidateAccessToken 验证访问令牌，需要修改密码的用户在修改密码前无法使用其他功能
 func ValidateAccessToken(accessToken string) (uint, error) {
+	userID, err := ValidatePasswordChangeToken(accessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkPasswordChangeRequired(userID); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// checkPasswordChangeRequired 用户被标记为需要修改密码时返回 ErrPasswordChangeRequired
+func checkPasswordChangeRequired(userID uint) error {
+	exists, err := config.RedisClient.Exists(context.Background(), passwordChangeRequiredKey(userID)).Result()
+	if err != nil {
+		return fmt.Errorf("验证密码状态失败: %v", err)
+	}
+	if exists == 1 {
+		return ErrPasswordChangeRequired
+	}
+	return nil
+}
+
+// ValidatePasswordChangeToken 验证访问令牌但不检查修改密码标记，仅用于修改密码接口
+func ValidatePasswordChangeToken(accessToken string) (uint, error) {
 	if accessToken == "" {
 		return 0, errors.New("令牌为空")
 	}
 
 	// 解析令牌
-	token, err := jwt.ParseWithClaims(accessToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("意外的签名方法: %v", token.Header["alg"])
-		}
-		return config.JWTSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(accessToken, &Claims{}, jwtKeyFunc)
 
 	if err != nil {
 		return 0, fmt.Errorf("解析令牌失败: %v", err)
@@ -188,6 +188,15 @@ func GetSubmissionDetail(submissionID uint64) (*models.SubmissionDetail, error)
 		return nil, fmt.Errorf("获��提交记录详情失败: %v", err)
 	}
 
+	// 作业提交附带批阅反馈
+	if detail.AssignmentID != nil {
+		feedback, err := GetSubmissionFeedback(detail.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取批阅反馈失败: %v", err)
+		}
+		detail.Feedback = feedback
+	}
+
 	return &detail, nil
 }
 
@@ -241,6 +250,10 @@ func judge(submission *models.Submission) error {
 		return fmt.Errorf("更新提交记录失败: %v", err)
 	}
 
+	if result.Status == models.StatusAccepted {
+		recordAssignmentAcceptedEvent(submission)
+	}
+
 	return nil
 }
 
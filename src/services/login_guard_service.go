@@ -0,0 +1,332 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 登录防暴力破解的阈值，失败次数在统计窗口内累计，登录成功后清零账号的失败次数
+const (
+	loginFailureWindow     = 30 * time.Minute
+	loginCaptchaAfter      = 3  // 账号失败次数达到后需要人机验证，并开始递增等待时间
+	loginIPCaptchaAfter    = 10 // IP失败次数达到后需要人机验证
+	loginLockAfter         = 10 // 账号失败次数达到后临时锁定
+	loginIPLockAfter       = 50 // IP失败次数达到后临时锁定
+	loginMaxDelay          = 30 * time.Second
+	loginAccountLockPeriod = 15 * time.Minute // 首次锁定时长，24 小时内再次锁定时加倍
+	loginMaxLockPeriod     = 24 * time.Hour
+	loginIPLockPeriod      = 30 * time.Minute
+	loginLocksIndexKey     = "login_locks" // 当前锁定的账号和IP，成员格式为 "类型|值"
+)
+
+// LoginAttemptStatus 登录前检查的结果
+type LoginAttemptStatus struct {
+	UserID          uint          // 登录账号对应的用户，账号不存在时为 0
+	Locked          bool          // 账号或IP已被临时锁定
+	RetryAfter      time.Duration // 需要等待的时间
+	CaptchaRequired bool          // 需要先完成人机验证
+
+	account string
+	ip      string
+}
+
+// CheckLoginAttempt 在验证密码之前检查账号和IP是否被锁定、是否需要等待或人机验证
+func CheckLoginAttempt(accountInfo string, ip string) (*LoginAttemptStatus, error) {
+	ctx := context.Background()
+	status := &LoginAttemptStatus{ip: ip}
+
+	var user models.User
+	err := config.DB.Select("id").Where("username = ? OR email = ? OR phone = ?",
+		accountInfo, accountInfo, accountInfo).First(&user).Error
+	if err == nil {
+		status.UserID = uint(user.ID)
+		status.account = userLoginAccount(status.UserID)
+	} else {
+		// 不存在的账号同样计数，避免通过响应差异判断账号是否存在
+		status.account = "name:" + strings.ToLower(strings.TrimSpace(accountInfo))
+	}
+	return checkLoginAttempt(ctx, status)
+}
+
+// CheckUserLoginAttempt 检查已确定用户的登录尝试，用于两步验证等密码之后的登录步骤，与密码登录共用失败次数
+func CheckUserLoginAttempt(userID uint, ip string) (*LoginAttemptStatus, error) {
+	status := &LoginAttemptStatus{
+		UserID:  userID,
+		account: userLoginAccount(userID),
+		ip:      ip,
+	}
+	return checkLoginAttempt(context.Background(), status)
+}
+
+// checkLoginAttempt 检查账号和IP的锁定、等待时间和人机验证要求
+func checkLoginAttempt(ctx context.Context, status *LoginAttemptStatus) (*LoginAttemptStatus, error) {
+	ip := status.ip
+
+	// 账号或IP锁定
+	for _, key := range []string{
+		loginLockKey(models.LoginLockAccount, status.account),
+		loginLockKey(models.LoginLockIP, ip),
+	} {
+		ttl, err := config.RedisClient.TTL(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ttl > 0 {
+			status.Locked = true
+			if ttl > status.RetryAfter {
+				status.RetryAfter = ttl
+			}
+		}
+	}
+	if status.Locked {
+		return status, nil
+	}
+
+	// 递增等待时间
+	ttl, err := config.RedisClient.PTTL(ctx, loginDelayKey(status.account)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		status.RetryAfter = ttl
+	}
+
+	accountFailures, ipFailures, err := loginFailureCounts(ctx, status.account, ip)
+	if err != nil {
+		return nil, err
+	}
+	status.CaptchaRequired = accountFailures >= loginCaptchaAfter || ipFailures >= loginIPCaptchaAfter
+	return status, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，按失败次数设置等待时间，达到阈值后锁定账号或IP；
+// 返回更新后的状态，用于提示下次登录是否需要人机验证
+func RecordLoginFailure(status *LoginAttemptStatus) (*LoginAttemptStatus, error) {
+	ctx := context.Background()
+
+	accountFailures, err := incrLoginFailure(ctx, loginFailureKey(models.LoginLockAccount, status.account))
+	if err != nil {
+		return nil, err
+	}
+	ipFailures, err := incrLoginFailure(ctx, loginFailureKey(models.LoginLockIP, status.ip))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LoginAttemptStatus{
+		UserID:          status.UserID,
+		CaptchaRequired: accountFailures >= loginCaptchaAfter || ipFailures >= loginIPCaptchaAfter,
+		account:         status.account,
+		ip:              status.ip,
+	}
+
+	if accountFailures >= loginLockAfter {
+		lockouts, err := config.RedisClient.Incr(ctx, "login_lockouts:"+status.account).Result()
+		if err != nil {
+			return nil, err
+		}
+		config.RedisClient.Expire(ctx, "login_lockouts:"+status.account, 24*time.Hour)
+
+		period := loginAccountLockPeriod << (lockouts - 1)
+		if period > loginMaxLockPeriod || period <= 0 {
+			period = loginMaxLockPeriod
+		}
+		if err := lockLogin(ctx, models.LoginLockAccount, status.account, period); err != nil {
+			return nil, err
+		}
+		// 锁定后重新计数
+		config.RedisClient.Del(ctx, loginFailureKey(models.LoginLockAccount, status.account), loginDelayKey(status.account))
+		result.Locked = true
+		result.RetryAfter = period
+	} else if accountFailures >= loginCaptchaAfter {
+		delay := time.Second << (accountFailures - loginCaptchaAfter)
+		if delay > loginMaxDelay {
+			delay = loginMaxDelay
+		}
+		if err := config.RedisClient.Set(ctx, loginDelayKey(status.account), 1, delay).Err(); err != nil {
+			return nil, err
+		}
+		result.RetryAfter = delay
+	}
+
+	if ipFailures >= loginIPLockAfter {
+		if err := lockLogin(ctx, models.LoginLockIP, status.ip, loginIPLockPeriod); err != nil {
+			return nil, err
+		}
+		config.RedisClient.Del(ctx, loginFailureKey(models.LoginLockIP, status.ip))
+		result.Locked = true
+		if loginIPLockPeriod > result.RetryAfter {
+			result.RetryAfter = loginIPLockPeriod
+		}
+	}
+
+	return result, nil
+}
+
+// ClearUserLoginFailures 登录完成（签发令牌）后清除账号的失败次数和等待时间
+func ClearUserLoginFailures(userID uint) error {
+	account := userLoginAccount(userID)
+	return config.RedisClient.Del(context.Background(),
+		loginFailureKey(models.LoginLockAccount, account),
+		loginDelayKey(account),
+	).Err()
+}
+
+// ClearUserLoginLock 解除用户账号的锁定，用于重置密码后
+func ClearUserLoginLock(userID uint) error {
+	return unlockLogin(context.Background(), models.LoginLockAccount, userLoginAccount(userID))
+}
+
+// ConsumeLoginCaptcha 校验并作废 Geetest 验证通过后返回的 captchaID，每个 captchaID 只能用于一次登录
+func ConsumeLoginCaptcha(captchaID string) bool {
+	if captchaID == "" {
+		return false
+	}
+	val, err := config.RedisClient.GetDel(context.Background(), captchaID).Result()
+	return err == nil && val == "geetest:result:success"
+}
+
+// GetLoginLocks 获取当前被锁定的账号和IP
+func GetLoginLocks() ([]models.LoginLock, error) {
+	ctx := context.Background()
+	members, err := config.RedisClient.SMembers(ctx, loginLocksIndexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	locks := make([]models.LoginLock, 0, len(members))
+	for _, member := range members {
+		lockType, value, ok := strings.Cut(member, "|")
+		if !ok {
+			config.RedisClient.SRem(ctx, loginLocksIndexKey, member)
+			continue
+		}
+
+		ttl, err := config.RedisClient.TTL(ctx, loginLockKey(lockType, value)).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ttl <= 0 {
+			// 锁定已过期
+			config.RedisClient.SRem(ctx, loginLocksIndexKey, member)
+			continue
+		}
+
+		lock := models.LoginLock{
+			Type:        lockType,
+			Value:       value,
+			LockedUntil: time.Now().Add(ttl),
+		}
+		lock.Failures, err = config.RedisClient.Get(ctx, loginLockKey(lockType, value)).Int64()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		if lockType == models.LoginLockAccount && strings.HasPrefix(value, "user:") {
+			if id, err := strconv.ParseUint(strings.TrimPrefix(value, "user:"), 10, 64); err == nil {
+				var user models.User
+				if err := config.DB.Select("id", "username").First(&user, id).Error; err == nil {
+					lock.UserID = user.ID
+					lock.Username = user.Username
+				}
+			}
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// UnlockLogin 管理员解除账号或IP的锁定，同时清除失败次数
+func UnlockLogin(req *models.UnlockLoginRequest) error {
+	ctx := context.Background()
+	exists, err := config.RedisClient.Exists(ctx, loginLockKey(req.Type, req.Value)).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return errors.New("该账号或IP未被锁定")
+	}
+	return unlockLogin(ctx, req.Type, req.Value)
+}
+
+// unlockLogin 删除锁定、失败次数和等待时间
+func unlockLogin(ctx context.Context, lockType string, value string) error {
+	keys := []string{loginLockKey(lockType, value), loginFailureKey(lockType, value)}
+	if lockType == models.LoginLockAccount {
+		keys = append(keys, loginDelayKey(value), "login_lockouts:"+value)
+	}
+	if err := config.RedisClient.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	return config.RedisClient.SRem(ctx, loginLocksIndexKey, lockType+"|"+value).Err()
+}
+
+// lockLogin 锁定账号或IP，锁定键的值为锁定时的失败次数
+func lockLogin(ctx context.Context, lockType string, value string, period time.Duration) error {
+	failures, err := config.RedisClient.Get(ctx, loginFailureKey(lockType, value)).Int64()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	if err := config.RedisClient.Set(ctx, loginLockKey(lockType, value), failures, period).Err(); err != nil {
+		return err
+	}
+	return config.RedisClient.SAdd(ctx, loginLocksIndexKey, lockType+"|"+value).Err()
+}
+
+// incrLoginFailure 增加失败次数，统计窗口从第一次失败开始计算
+func incrLoginFailure(ctx context.Context, key string) (int64, error) {
+	count, err := config.RedisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		config.RedisClient.Expire(ctx, key, loginFailureWindow)
+	}
+	return count, nil
+}
+
+// loginFailureCounts 获取账号和IP的失败次数
+func loginFailureCounts(ctx context.Context, account string, ip string) (int64, int64, error) {
+	values, err := config.RedisClient.MGet(ctx,
+		loginFailureKey(models.LoginLockAccount, account),
+		loginFailureKey(models.LoginLockIP, ip),
+	).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	counts := make([]int64, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			counts[i], _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return counts[0], counts[1], nil
+}
+
+// userLoginAccount 已存在用户的账号标识
+func userLoginAccount(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// loginFailureKey 失败次数在 Redis 中的键
+func loginFailureKey(lockType string, value string) string {
+	return "login_failures:" + lockType + ":" + value
+}
+
+// loginLockKey 锁定标记在 Redis 中的键
+func loginLockKey(lockType string, value string) string {
+	return "login_lock:" + lockType + ":" + value
+}
+
+// loginDelayKey 登录等待时间在 Redis 中的键
+func loginDelayKey(account string) string {
+	return "login_delay:" + account
+}
@@ -73,8 +73,8 @@ func GetMessageList(userID uint64, req *models.MessageListRequest) (*models.Mess
 			var application models.TeamApplication
 			if err := config.DB.Where("id = ?", messages[i].ApplicationID).
 				First(&application).Error; err == nil {
-				role, err := GetTeamUserRole(application.TeamID, userID)
-				if err == nil && (role == "owner" || role == "admin") {
+				canManage, err := HasTeamPermission(application.TeamID, userID, models.TeamPermManageMembers)
+				if err == nil && canManage {
 					isAdmin = true
 				}
 			}
@@ -252,6 +252,9 @@ func CreateTeamApplication(userID uint64, req *models.TeamApplicationRequest) er
 	if isMember {
 		return fmt.Errorf("您已经是团队成员")
 	}
+	if err := checkTeamWritable(req.TeamID); err != nil {
+		return err
+	}
 
 	// 检查是否有待处理的申请
 	var count int64
@@ -330,13 +333,9 @@ func GetTeamApplicationList(req *models.TeamApplicationListRequest, userID uint6
 
 	// 如果指定了团队ID，检查用户权限
 	if req.TeamID > 0 {
-		role, err := GetTeamUserRole(req.TeamID, userID)
-		if err != nil {
+		if _, err := AuthorizeTeam(req.TeamID, userID, models.TeamPermManageMembers); err != nil {
 			return nil, err
 		}
-		if role != "owner" && role != "admin" {
-			return nil, fmt.Errorf("权限不足")
-		}
 		query = query.Where("team_id = ?", req.TeamID)
 	} else {
 		// 如果没有指定团队ID，只能查看自己的申请
@@ -398,13 +397,9 @@ func HandleTeamApplication(req *models.TeamApplicationHandleRequest, operatorID
 	}
 
 	// 检查操作者权限
-	role, err := GetTeamUserRole(application.TeamID, operatorID)
-	if err != nil {
+	if _, err := AuthorizeTeam(application.TeamID, operatorID, models.TeamPermManageMembers); err != nil {
 		return err
 	}
-	if role != "owner" && role != "admin" {
-		return fmt.Errorf("权限不足")
-	}
 
 	// 检查申请状态
 	if application.Status != "pending" {
@@ -424,12 +419,15 @@ func HandleTeamApplication(req *models.TeamApplicationHandleRequest, operatorID
 			member := &models.TeamMember{
 				TeamID:   application.TeamID,
 				UserID:   application.UserID,
-				Role:     "member",
+				Role:     models.TeamRoleMember,
 				JoinedAt: time.Now(),
 			}
 			if err := tx.Create(member).Error; err != nil {
 				return err
 			}
+			if err := recordTeamEvent(tx, application.TeamID, &application.UserID, models.TeamEventMemberJoined, application.UserID, "通过申请加入了团队"); err != nil {
+				return err
+			}
 		}
 
 		// 获取团队信息
@@ -0,0 +1,691 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStateTTL         = 10 * time.Minute
+	oidcDiscoveryTTL     = time.Hour
+	oidcJWKSRefreshLimit = time.Minute // 遇到未知 kid 时重新获取 JWKS 的最小间隔
+)
+
+// ErrOIDCDisabled 未启用单点登录
+var ErrOIDCDisabled = errors.New("未启用单点登录")
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+var invalidUsernameChars = regexp.MustCompile(`[^A-Za-z0-9_\-]`)
+
+// oidcEndpoints 身份提供方的端点，来自配置或发现文档
+type oidcEndpoints struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcProvider 缓存的发现文档和 ID Token 验证公钥
+type oidcProvider struct {
+	mu            sync.Mutex
+	endpoints     *oidcEndpoints
+	discoveredAt  time.Time
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+var oidcIdP = &oidcProvider{}
+
+// oidcLoginState 授权请求中保存在 Redis 的状态
+type oidcLoginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// oidcTokenResponse 令牌端点的响应
+type oidcTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+// oidcIdentity 从声明中取出的用户身份
+type oidcIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+}
+
+// GetOIDCProviderInfo 获取单点登录配置信息
+func GetOIDCProviderInfo() models.OIDCProviderInfo {
+	return models.OIDCProviderInfo{
+		Enabled: config.OIDC.Enabled,
+		Name:    config.OIDC.Name,
+	}
+}
+
+// GetOIDCAuthorizationURL 生成跳转到身份提供方的授权地址，使用 state、nonce 和 PKCE 防止授权码被劫持
+func GetOIDCAuthorizationURL() (string, error) {
+	if !config.OIDC.Enabled {
+		return "", ErrOIDCDisabled
+	}
+	endpoints, err := oidcIdP.getEndpoints()
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomURLString(24)
+	if err != nil {
+		return "", err
+	}
+	loginState := oidcLoginState{}
+	if loginState.Nonce, err = randomURLString(24); err != nil {
+		return "", err
+	}
+	if loginState.CodeVerifier, err = randomURLString(32); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(loginState)
+	if err != nil {
+		return "", err
+	}
+	if err := config.RedisClient.Set(context.Background(), oidcStateKey(state), data, oidcStateTTL).Err(); err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(loginState.CodeVerifier))
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", config.OIDC.ClientID)
+	query.Set("redirect_uri", config.OIDC.RedirectURL)
+	query.Set("scope", strings.Join(oidcScopes(), " "))
+	query.Set("state", state)
+	query.Set("nonce", loginState.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(endpoints.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return endpoints.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// OIDCLogin 使用授权码完成单点登录，返回关联或新创建的用户
+func OIDCLogin(req *models.OIDCCallbackRequest) (*models.User, error) {
+	if !config.OIDC.Enabled {
+		return nil, ErrOIDCDisabled
+	}
+
+	// state 只能使用一次
+	data, err := config.RedisClient.GetDel(context.Background(), oidcStateKey(req.State)).Result()
+	if err != nil {
+		return nil, errors.New("登录状态无效或已过期，请重新登录")
+	}
+	var loginState oidcLoginState
+	if err := json.Unmarshal([]byte(data), &loginState); err != nil {
+		return nil, err
+	}
+
+	providerID, identity, err := authenticateOIDC(req.Code, &loginState)
+	if err != nil {
+		return nil, err
+	}
+	return resolveOIDCUser(providerID, identity)
+}
+
+// authenticateOIDC 用授权码换取令牌，验证 ID Token 并合并用户信息接口的声明，返回身份提供方标识和用户身份
+func authenticateOIDC(code string, loginState *oidcLoginState) (string, *oidcIdentity, error) {
+	endpoints, err := oidcIdP.getEndpoints()
+	if err != nil {
+		return "", nil, err
+	}
+	tokens, err := exchangeOIDCCode(endpoints, code, loginState.CodeVerifier)
+	if err != nil {
+		return "", nil, err
+	}
+
+	claims := map[string]interface{}{}
+	if tokens.IDToken != "" {
+		if claims, err = oidcIdP.verifyIDToken(tokens.IDToken, loginState.Nonce); err != nil {
+			return "", nil, err
+		}
+	} else if endpoints.UserInfoEndpoint == "" {
+		return "", nil, errors.New("身份提供方未返回 ID Token，且未配置用户信息接口")
+	}
+
+	// ID Token 中缺少邮箱等信息时，从用户信息接口补充
+	if endpoints.UserInfoEndpoint != "" && tokens.AccessToken != "" {
+		userInfo, err := fetchOIDCUserInfo(endpoints.UserInfoEndpoint, tokens.AccessToken)
+		if err != nil {
+			return "", nil, err
+		}
+		subjectClaim := oidcClaimName(config.OIDC.Claims.Subject, "sub")
+		if sub, ok := claims[subjectClaim]; ok && fmt.Sprint(userInfo[subjectClaim]) != fmt.Sprint(sub) {
+			return "", nil, errors.New("用户信息与 ID Token 不一致")
+		}
+		for k, v := range userInfo {
+			if _, exists := claims[k]; !exists {
+				claims[k] = v
+			}
+		}
+	}
+
+	identity := mapOIDCClaims(claims)
+	if identity.Subject == "" {
+		return "", nil, errors.New("身份提供方未返回用户标识")
+	}
+	return oidcProviderID(endpoints), identity, nil
+}
+
+// resolveOIDCUser 查找外部身份关联的账号：已关联的直接返回，否则按邮箱关联已有账号，都没有时按配置自动创建
+func resolveOIDCUser(providerID string, identity *oidcIdentity) (*models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var linked models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", providerID, identity.Subject).First(&linked).Error
+		if err == nil {
+			if err := tx.Model(&linked).Updates(map[string]interface{}{
+				"email":         identity.Email,
+				"last_login_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.First(&user, linked.UserID).Error
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		err = gorm.ErrRecordNotFound
+		if identity.Email != "" {
+			err = tx.Where("email = ?", identity.Email).First(&user).Error
+		}
+		switch {
+		case err == nil:
+			// 管理员账号不按邮箱自动关联，防止通过身份提供方接管管理员账号
+			adminRole, err := GetAdminRole(uint(user.ID))
+			if err != nil {
+				return err
+			}
+			if err := checkOIDCEmailLink(identity, adminRole); err != nil {
+				return err
+			}
+		case err != gorm.ErrRecordNotFound:
+			return err
+		case !config.OIDC.AutoProvision:
+			return errors.New("未找到关联的账号，请联系管理员")
+		default:
+			if err := provisionOIDCUser(tx, identity, &user); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:      user.ID,
+			Provider:    providerID,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			CreatedAt:   now,
+			LastLoginAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// checkOIDCEmailLink 检查外部身份能否按邮箱关联已有账号：邮箱需经身份提供方确认（除非配置允许），且不能是管理员账号
+func checkOIDCEmailLink(identity *oidcIdentity, adminRole string) error {
+	if !identity.EmailVerified && !config.OIDC.AllowUnverifiedEmailLink {
+		return errors.New("该邮箱已注册，但身份提供方未确认邮箱，无法自动关联账号")
+	}
+	if adminRole != "" {
+		return errors.New("管理员账号不能自动关联单点登录身份，请联系超级管理员")
+	}
+	return nil
+}
+
+// provisionOIDCUser 为外部身份创建账号，密码随机生成，用户可通过找回密码设置本地密码
+func provisionOIDCUser(tx *gorm.DB, identity *oidcIdentity, user *models.User) error {
+	if identity.Email == "" {
+		return errors.New("身份提供方未返回邮箱，无法创建账号")
+	}
+
+	username, err := uniqueUsername(tx, identity)
+	if err != nil {
+		return err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(raw)), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	*user = models.User{
+		Username: username,
+		Password: string(hashedPassword),
+		Email:    identity.Email,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return tx.Create(&models.Profile{
+		UserID:   int(user.ID),
+		CreateAt: now,
+		UpdateAt: now,
+	}).Error
+}
+
+// uniqueUsername 根据声明中的用户名或邮箱生成未被占用的用户名
+func uniqueUsername(tx *gorm.DB, identity *oidcIdentity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	base = invalidUsernameChars.ReplaceAllString(base, "")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 10; i++ {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		suffix, err := rand.Int(rand.Reader, big.NewInt(1000000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%06d", base, suffix.Int64())
+	}
+	return "", errors.New("生成用户名失败")
+}
+
+// getEndpoints 获取身份提供方的端点，配置中未指定的端点从发现文档获取
+func (p *oidcProvider) getEndpoints() (*oidcEndpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.endpoints != nil && time.Since(p.discoveredAt) < oidcDiscoveryTTL {
+		return p.endpoints, nil
+	}
+
+	cfg := config.OIDC
+	endpoints := &oidcEndpoints{
+		Issuer:                cfg.Issuer,
+		AuthorizationEndpoint: cfg.AuthorizationEndpoint,
+		TokenEndpoint:         cfg.TokenEndpoint,
+		UserInfoEndpoint:      cfg.UserInfoEndpoint,
+		JWKSURI:               cfg.JWKSURI,
+	}
+
+	if endpoints.AuthorizationEndpoint == "" || endpoints.TokenEndpoint == "" {
+		if endpoints.Issuer == "" {
+			return nil, errors.New("单点登录配置缺少 issuer 或授权端点")
+		}
+		var discovered oidcEndpoints
+		if err := getJSON(strings.TrimSuffix(endpoints.Issuer, "/")+"/.well-known/openid-configuration", "", &discovered); err != nil {
+			return nil, fmt.Errorf("获取身份提供方配置失败: %v", err)
+		}
+		if strings.TrimSuffix(discovered.Issuer, "/") != strings.TrimSuffix(endpoints.Issuer, "/") {
+			return nil, errors.New("身份提供方的 issuer 与配置不一致")
+		}
+		endpoints.Issuer = discovered.Issuer
+		if endpoints.AuthorizationEndpoint == "" {
+			endpoints.AuthorizationEndpoint = discovered.AuthorizationEndpoint
+		}
+		if endpoints.TokenEndpoint == "" {
+			endpoints.TokenEndpoint = discovered.TokenEndpoint
+		}
+		if endpoints.UserInfoEndpoint == "" {
+			endpoints.UserInfoEndpoint = discovered.UserInfoEndpoint
+		}
+		if endpoints.JWKSURI == "" {
+			endpoints.JWKSURI = discovered.JWKSURI
+		}
+	}
+
+	p.endpoints = endpoints
+	p.discoveredAt = time.Now()
+	return endpoints, nil
+}
+
+// verifyIDToken 验证 ID Token 的签名、issuer、audience、有效期和 nonce，返回其中的声明
+func (p *oidcProvider) verifyIDToken(idToken string, nonce string) (map[string]interface{}, error) {
+	endpoints, err := p.getEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithAudience(config.OIDC.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	}
+	if endpoints.Issuer != "" {
+		options = append(options, jwt.WithIssuer(endpoints.Issuer))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(endpoints.JWKSURI, kid)
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("ID Token 验证失败: %v", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("ID Token 验证失败: nonce 不匹配")
+	}
+	return claims, nil
+}
+
+// publicKey 按 kid 查找 ID Token 验证公钥，找不到时重新获取 JWKS 以支持身份提供方轮换密钥
+func (p *oidcProvider) publicKey(jwksURI string, kid string) (interface{}, error) {
+	if jwksURI == "" {
+		return nil, errors.New("未配置 jwks_uri")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < oidcJWKSRefreshLimit {
+		return nil, errors.New("未找到对应的公钥")
+	}
+
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := getJSON(jwksURI, "", &jwks); err != nil {
+		return nil, fmt.Errorf("获取公钥失败: %v", err)
+	}
+	keys := make(map[string]interface{})
+	for _, raw := range jwks.Keys {
+		id, key, err := parseJWK(raw)
+		if err != nil {
+			continue // 跳过不支持的密钥类型
+		}
+		keys[id] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, errors.New("未找到对应的公钥")
+}
+
+// lookupKey 查找已缓存的公钥；令牌没有 kid 且 JWKS 只有一个公钥时使用该公钥
+func (p *oidcProvider) lookupKey(kid string) interface{} {
+	if key, ok := p.keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// parseJWK 解析 JWKS 中用于签名的公钥，支持 RSA、EC 和 Ed25519
+func parseJWK(raw json.RawMessage) (string, interface{}, error) {
+	var jwk struct {
+		KeyType string `json:"kty"`
+		KeyID   string `json:"kid"`
+		Use     string `json:"use"`
+		Curve   string `json:"crv"`
+		N       string `json:"n"`
+		E       string `json:"e"`
+		X       string `json:"x"`
+		Y       string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", nil, errors.New("不是签名公钥")
+	}
+
+	decode := base64.RawURLEncoding.DecodeString
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.KeyID, &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return "", nil, errors.New("不支持的曲线")
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.KeyID, &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		x, err := decode(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		if jwk.Curve != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return "", nil, errors.New("不支持的曲线")
+		}
+		return jwk.KeyID, ed25519.PublicKey(x), nil
+	}
+	return "", nil, errors.New("不支持的密钥类型")
+}
+
+// exchangeOIDCCode 使用授权码换取令牌
+func exchangeOIDCCode(endpoints *oidcEndpoints, code string, codeVerifier string) (*oidcTokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", config.OIDC.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	httpReq, err := http.NewRequest(http.MethodPost, endpoints.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.SetBasicAuth(url.QueryEscape(config.OIDC.ClientID), url.QueryEscape(config.OIDC.ClientSecret))
+
+	resp, err := oidcHTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("请求令牌失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求令牌失败: %s", strings.TrimSpace(string(body)))
+	}
+
+	var tokens oidcTokenResponse
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("解析令牌响应失败: %v", err)
+	}
+	return &tokens, nil
+}
+
+// fetchOIDCUserInfo 获取用户信息接口返回的声明
+func fetchOIDCUserInfo(endpoint string, accessToken string) (map[string]interface{}, error) {
+	userInfo := map[string]interface{}{}
+	if err := getJSON(endpoint, accessToken, &userInfo); err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %v", err)
+	}
+	return userInfo, nil
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应，accessToken 不为空时携带 Bearer 令牌
+func getJSON(endpoint string, accessToken string, v interface{}) error {
+	httpReq, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := oidcHTTPClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// mapOIDCClaims 按配置的声明名称取出用户身份
+func mapOIDCClaims(claims map[string]interface{}) *oidcIdentity {
+	mapping := config.OIDC.Claims
+	str := func(name string) string {
+		switch v := claims[name].(type) {
+		case string:
+			return strings.TrimSpace(v)
+		case float64:
+			return fmt.Sprintf("%.0f", v)
+		case nil:
+			return ""
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+
+	verified := false
+	switch v := claims[oidcClaimName(mapping.EmailVerified, "email_verified")].(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = strings.EqualFold(v, "true")
+	}
+
+	return &oidcIdentity{
+		Subject:       str(oidcClaimName(mapping.Subject, "sub")),
+		Email:         strings.ToLower(str(oidcClaimName(mapping.Email, "email"))),
+		EmailVerified: verified,
+		Username:      str(oidcClaimName(mapping.Username, "preferred_username")),
+	}
+}
+
+// oidcClaimName 返回配置的声明名称，未配置时使用默认名称
+func oidcClaimName(configured string, fallback string) string {
+	if configured != "" {
+		return configured
+	}
+	return fallback
+}
+
+// oidcScopes 返回请求的 scope，未配置时使用 OIDC 的默认值
+func oidcScopes() []string {
+	if len(config.OIDC.Scopes) > 0 {
+		return config.OIDC.Scopes
+	}
+	return []string{"openid", "profile", "email"}
+}
+
+// oidcProviderID 外部身份关联记录中的身份提供方标识
+func oidcProviderID(endpoints *oidcEndpoints) string {
+	if endpoints.Issuer != "" {
+		return endpoints.Issuer
+	}
+	return endpoints.AuthorizationEndpoint
+}
+
+// oidcStateKey 授权请求状态在 Redis 中的键
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
+
+// randomURLString 生成 URL 安全的随机字符串
+func randomURLString(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdP 本地模拟的身份提供方，提供发现文档、令牌、用户信息和 JWKS 端点
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	code         string
+	codeVerifier string
+	accessToken  string
+
+	// 令牌端点签发的 ID Token 声明，以及用户信息接口返回的声明
+	idTokenClaims jwt.MapClaims
+	userInfo      map[string]interface{}
+
+	requests map[string]int
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{
+		key:          key,
+		code:         "test-code",
+		codeVerifier: "test-verifier",
+		accessToken:  "test-access-token",
+		requests:     make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		idp.requests["discovery"]++
+		writeTestJSON(w, map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"userinfo_endpoint":      idp.server.URL + "/userinfo",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		idp.requests["token"]++
+		clientID, clientSecret, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || clientID != config.OIDC.ClientID || clientSecret != config.OIDC.ClientSecret ||
+			r.FormValue("grant_type") != "authorization_code" || r.FormValue("code") != idp.code ||
+			r.FormValue("code_verifier") != idp.codeVerifier {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.idTokenClaims)
+		token.Header["kid"] = "test-key"
+		idToken, err := token.SignedString(idp.key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeTestJSON(w, map[string]string{
+			"access_token": idp.accessToken,
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		idp.requests["userinfo"]++
+		if r.Header.Get("Authorization") != "Bearer "+idp.accessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeTestJSON(w, idp.userInfo)
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.requests["jwks"]++
+		writeTestJSON(w, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	now := time.Now()
+	idp.idTokenClaims = jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"sub":   "student-1",
+		"aud":   "optioj",
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"nonce": "test-nonce",
+	}
+	idp.userInfo = map[string]interface{}{
+		"sub":                "student-1",
+		"email":              "Student1@Example.edu",
+		"email_verified":     true,
+		"preferred_username": "student1",
+	}
+	return idp
+}
+
+func writeTestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// useMockIdP 将单点登录配置指向模拟身份提供方，并清空缓存的发现文档和公钥
+func useMockIdP(t *testing.T, idp *mockIdP) {
+	t.Helper()
+
+	saved := config.OIDC
+	t.Cleanup(func() {
+		config.OIDC = saved
+		oidcIdP = &oidcProvider{}
+	})
+	config.OIDC = config.OIDCConfig{
+		Enabled:      true,
+		Issuer:       idp.server.URL,
+		ClientID:     "optioj",
+		ClientSecret: "test-secret",
+		RedirectURL:  "http://localhost/login/sso/callback",
+	}
+	oidcIdP = &oidcProvider{}
+}
+
+func TestAuthenticateOIDC(t *testing.T) {
+	idp := newMockIdP(t)
+	useMockIdP(t, idp)
+
+	providerID, identity, err := authenticateOIDC(idp.code, &oidcLoginState{Nonce: "test-nonce", CodeVerifier: idp.codeVerifier})
+	if err != nil {
+		t.Fatalf("authenticateOIDC: %v", err)
+	}
+	if providerID != idp.server.URL {
+		t.Errorf("providerID = %q, want %q", providerID, idp.server.URL)
+	}
+	want := oidcIdentity{Subject: "student-1", Email: "student1@example.edu", EmailVerified: true, Username: "student1"}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+	for _, endpoint := range []string{"discovery", "token", "userinfo", "jwks"} {
+		if idp.requests[endpoint] != 1 {
+			t.Errorf("%s requested %d times, want 1", endpoint, idp.requests[endpoint])
+		}
+	}
+}
+
+func TestAuthenticateOIDCRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(idp *mockIdP, state *oidcLoginState)
+		wantErr string
+	}{
+		{
+			name:    "nonce mismatch",
+			modify:  func(idp *mockIdP, state *oidcLoginState) { state.Nonce = "another-nonce" },
+			wantErr: "nonce",
+		},
+		{
+			name:    "wrong audience",
+			modify:  func(idp *mockIdP, state *oidcLoginState) { idp.idTokenClaims["aud"] = "another-client" },
+			wantErr: "ID Token 验证失败",
+		},
+		{
+			name:    "wrong issuer",
+			modify:  func(idp *mockIdP, state *oidcLoginState) { idp.idTokenClaims["iss"] = "https://evil.example.com" },
+			wantErr: "ID Token 验证失败",
+		},
+		{
+			name: "expired",
+			modify: func(idp *mockIdP, state *oidcLoginState) {
+				idp.idTokenClaims["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+			wantErr: "ID Token 验证失败",
+		},
+		{
+			name:    "wrong code verifier",
+			modify:  func(idp *mockIdP, state *oidcLoginState) { state.CodeVerifier = "another-verifier" },
+			wantErr: "请求令牌失败",
+		},
+		{
+			name: "userinfo subject mismatch",
+			modify: func(idp *mockIdP, state *oidcLoginState) {
+				idp.userInfo["sub"] = "student-2"
+			},
+			wantErr: "用户信息与 ID Token 不一致",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			useMockIdP(t, idp)
+			state := &oidcLoginState{Nonce: "test-nonce", CodeVerifier: idp.codeVerifier}
+			tt.modify(idp, state)
+
+			_, _, err := authenticateOIDC(idp.code, state)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckOIDCEmailLink(t *testing.T) {
+	idp := newMockIdP(t)
+	useMockIdP(t, idp)
+	idp.userInfo["email_verified"] = false
+
+	_, identity, err := authenticateOIDC(idp.code, &oidcLoginState{Nonce: "test-nonce", CodeVerifier: idp.codeVerifier})
+	if err != nil {
+		t.Fatalf("authenticateOIDC: %v", err)
+	}
+	if identity.EmailVerified {
+		t.Fatal("EmailVerified = true, want false")
+	}
+
+	// 默认不按未确认的邮箱关联账号
+	if err := checkOIDCEmailLink(identity, ""); err == nil {
+		t.Error("unverified email linked by default")
+	}
+
+	config.OIDC.AllowUnverifiedEmailLink = true
+	if err := checkOIDCEmailLink(identity, ""); err != nil {
+		t.Errorf("unverified email refused with allow_unverified_email_link: %v", err)
+	}
+
+	// 管理员账号始终不自动关联
+	identity.EmailVerified = true
+	if err := checkOIDCEmailLink(identity, "super_admin"); err == nil {
+		t.Error("admin account linked by email")
+	}
+}
@@ -0,0 +1,515 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 组织层级的最大深度，防止数据异常导致的死循环
+const maxOrganizationDepth = 16
+
+// getOrganization 获取组织
+func getOrganization(orgID uint64) (*models.Organization, error) {
+	var org models.Organization
+	if err := config.DB.First(&org, orgID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("组织不存在")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// getOrganizationAncestorIDs 获取组织及其全部上级组织的ID，从自身开始向上排列
+func getOrganizationAncestorIDs(orgID uint64) ([]uint64, error) {
+	ids := make([]uint64, 0)
+	current := &orgID
+	for depth := 0; current != nil && depth < maxOrganizationDepth; depth++ {
+		var org models.Organization
+		if err := config.DB.Select("id, parent_id").First(&org, *current).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				break
+			}
+			return nil, err
+		}
+		ids = append(ids, org.ID)
+		current = org.ParentID
+	}
+	return ids, nil
+}
+
+// getOrganizationDescendantIDs 获取组织及其全部下级组织的ID
+func getOrganizationDescendantIDs(orgID uint64) ([]uint64, error) {
+	ids := []uint64{orgID}
+	level := []uint64{orgID}
+	for depth := 0; len(level) > 0 && depth < maxOrganizationDepth; depth++ {
+		var children []uint64
+		if err := config.DB.Model(&models.Organization{}).
+			Where("parent_id IN ?", level).
+			Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		level = children
+	}
+	return ids, nil
+}
+
+// IsOrganizationAdmin 判断用户是否可以管理该组织：拥有 org.manage 权限的用户、该组织或任一上级组织的管理员
+func IsOrganizationAdmin(orgID uint64, userID uint64) (bool, error) {
+	isAdmin, err := HasSitePermission(userID, models.SitePermOrgManage)
+	if err != nil {
+		return false, err
+	}
+	if isAdmin {
+		return true, nil
+	}
+
+	ancestors, err := getOrganizationAncestorIDs(orgID)
+	if err != nil {
+		return false, err
+	}
+	if len(ancestors) == 0 {
+		return false, nil
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.OrganizationAdmin{}).
+		Where("org_id IN ? AND user_id = ?", ancestors, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// isTeamOrganizationAdmin 判断用户是否为团队所属组织（含上级组织）的管理员
+func isTeamOrganizationAdmin(teamID uint64, userID uint64) (bool, error) {
+	var team models.Team
+	if err := config.DB.Select("id, org_id").First(&team, teamID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if team.OrgID == nil {
+		return false, nil
+	}
+	return IsOrganizationAdmin(*team.OrgID, userID)
+}
+
+// checkOrganizationAdmin 检查用户是否可以管理该组织
+func checkOrganizationAdmin(orgID uint64, userID uint64) error {
+	allowed, err := IsOrganizationAdmin(orgID, userID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("权限不足")
+	}
+	return nil
+}
+
+// CreateOrganization 创建组织；顶级组织只能由拥有 org.manage 权限的用户创建，下级组织由上级组织管理员创建
+func CreateOrganization(req *models.CreateOrganizationRequest, userID uint64) (*models.Organization, error) {
+	if req.ParentID == nil {
+		isAdmin, err := HasSitePermission(userID, models.SitePermOrgManage)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, errors.New("只有管理员可以创建顶级组织")
+		}
+	} else {
+		if _, err := getOrganization(*req.ParentID); err != nil {
+			return nil, err
+		}
+		if err := checkOrganizationAdmin(*req.ParentID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	org := &models.Organization{
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Type:        req.Type,
+		Description: req.Description,
+		CreatedBy:   userID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := config.DB.Create(org).Error; err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// UpdateOrganization 更新组织信息
+func UpdateOrganization(orgID uint64, req *models.UpdateOrganizationRequest, userID uint64) error {
+	if _, err := getOrganization(orgID); err != nil {
+		return err
+	}
+	if err := checkOrganizationAdmin(orgID, userID); err != nil {
+		return err
+	}
+
+	return config.DB.Model(&models.Organization{}).Where("id = ?", orgID).Updates(map[string]interface{}{
+		"name":        req.Name,
+		"description": req.Description,
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+// DeleteOrganization 删除组织，组织下仍有下级组织或团队时不能删除
+func DeleteOrganization(orgID uint64, userID uint64) error {
+	if _, err := getOrganization(orgID); err != nil {
+		return err
+	}
+	if err := checkOrganizationAdmin(orgID, userID); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.Organization{}).Where("parent_id = ?", orgID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("该组织下仍有下级组织，无法删除")
+	}
+	if err := config.DB.Model(&models.Team{}).Where("org_id = ?", orgID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("该组织下仍有团队，无法删除")
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// 解除用户资料中的学校关联
+		if err := tx.Model(&models.Profile{}).Where("school_org_id = ?", orgID).Updates(map[string]interface{}{
+			"school_org_id":   nil,
+			"school_verified": false,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Organization{}, orgID).Error
+	})
+}
+
+// GetOrganizationTree 获取组织树，rootID 为 0 时返回全部顶级组织
+func GetOrganizationTree(rootID uint64) ([]*models.OrganizationNode, error) {
+	var orgs []models.Organization
+	if err := config.DB.Order("id").Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+
+	// 统计各组织直属团队数量
+	var counts []struct {
+		OrgID uint64
+		Count int
+	}
+	if err := config.DB.Model(&models.Team{}).
+		Select("org_id, COUNT(*) as count").
+		Where("org_id IS NOT NULL").
+		Group("org_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	teamCounts := make(map[uint64]int, len(counts))
+	for _, c := range counts {
+		teamCounts[c.OrgID] = c.Count
+	}
+
+	nodes := make(map[uint64]*models.OrganizationNode, len(orgs))
+	for _, org := range orgs {
+		nodes[org.ID] = &models.OrganizationNode{
+			Organization: org,
+			TeamCount:    teamCounts[org.ID],
+			Children:     make([]*models.OrganizationNode, 0),
+		}
+	}
+
+	roots := make([]*models.OrganizationNode, 0)
+	for _, org := range orgs {
+		node := nodes[org.ID]
+		if org.ParentID != nil {
+			if parent, ok := nodes[*org.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		if rootID == 0 {
+			roots = append(roots, node)
+		}
+	}
+
+	if rootID != 0 {
+		node, ok := nodes[rootID]
+		if !ok {
+			return nil, errors.New("组织不存在")
+		}
+		roots = append(roots, node)
+	}
+	return roots, nil
+}
+
+// AddOrganizationAdmin 添加组织管理员
+func AddOrganizationAdmin(orgID uint64, targetUserID uint64, operatorID uint64) error {
+	org, err := getOrganization(orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkOrganizationAdmin(orgID, operatorID); err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := config.DB.Select("id").First(&user, targetUserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+
+	if err := config.DB.Exec(`
+		INSERT IGNORE INTO organization_admins (org_id, user_id)
+		VALUES (?, ?)
+	`, orgID, targetUserID).Error; err != nil {
+		return err
+	}
+
+	if targetUserID == operatorID {
+		return nil
+	}
+	return CreateMessage(&operatorID, targetUserID, models.MessageTypeTeamNotice,
+		"组织管理员任命 - "+org.Name, "您已被设置为组织 "+org.Name+" 的管理员，可以管理该组织下的所有团队")
+}
+
+// RemoveOrganizationAdmin 移除组织管理员
+func RemoveOrganizationAdmin(orgID uint64, targetUserID uint64, operatorID uint64) error {
+	if err := checkOrganizationAdmin(orgID, operatorID); err != nil {
+		return err
+	}
+
+	result := config.DB.Where("org_id = ? AND user_id = ?", orgID, targetUserID).Delete(&models.OrganizationAdmin{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该用户不是组织管理员")
+	}
+	return nil
+}
+
+// GetOrganizationAdmins 获取组织管理员列表（不含上级组织的管理员），只有组织管理员和组织下团队的成员可以查看
+func GetOrganizationAdmins(orgID uint64, userID uint64) ([]models.OrganizationAdminInfo, error) {
+	if _, err := getOrganization(orgID); err != nil {
+		return nil, err
+	}
+	allowed, err := canViewOrganization(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("权限不足")
+	}
+
+	admins := make([]models.OrganizationAdminInfo, 0)
+	if err := config.DB.Table("organization_admins oa").
+		Select("oa.user_id, u.username, oa.created_at").
+		Joins("JOIN users u ON u.id = oa.user_id").
+		Where("oa.org_id = ?", orgID).
+		Order("oa.created_at").
+		Scan(&admins).Error; err != nil {
+		return nil, err
+	}
+	return admins, nil
+}
+
+// SetTeamOrganization 设置团队所属组织；需要团队管理权限，且加入组织时需要是目标组织的管理员；
+// 离开组织会失去组织管理员的监管，只有团队所有者、当前组织管理员或拥有 org.manage 权限的用户可以操作
+func SetTeamOrganization(teamID uint64, req *models.SetTeamOrganizationRequest, operatorID uint64) error {
+	role, err := AuthorizeTeam(teamID, operatorID, models.TeamPermManageTeam)
+	if err != nil {
+		return err
+	}
+
+	if req.OrgID == nil && role != models.TeamRoleOwner && role != models.TeamRoleOrgAdmin {
+		return errors.New("只有团队所有者或组织管理员可以将团队移出组织")
+	}
+	if req.OrgID != nil {
+		if _, err := getOrganization(*req.OrgID); err != nil {
+			return err
+		}
+		if err := checkOrganizationAdmin(*req.OrgID, operatorID); err != nil {
+			return err
+		}
+	}
+
+	return config.DB.Model(&models.Team{}).Where("id = ?", teamID).Updates(map[string]interface{}{
+		"org_id":     req.OrgID,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// getTeamOrganizationPoolIDs 获取团队可见的组织题库，即团队所属组织及其全部上级组织
+func getTeamOrganizationPoolIDs(teamID uint64) ([]uint64, error) {
+	var team models.Team
+	if err := config.DB.Select("id, org_id").First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+	if team.OrgID == nil {
+		return []uint64{}, nil
+	}
+	return getOrganizationAncestorIDs(*team.OrgID)
+}
+
+// canViewOrganization 判断用户是否可以查看组织的管理员和题库：组织管理员，或该组织下任一团队的成员
+func canViewOrganization(orgID uint64, userID uint64) (bool, error) {
+	isOrgAdmin, err := IsOrganizationAdmin(orgID, userID)
+	if err != nil || isOrgAdmin {
+		return isOrgAdmin, err
+	}
+
+	descendants, err := getOrganizationDescendantIDs(orgID)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	if err := config.DB.Table("team_members tm").
+		Joins("JOIN teams t ON t.id = tm.team_id").
+		Where("tm.user_id = ? AND t.org_id IN ?", userID, descendants).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AddOrganizationProblem 将题目加入组织题库，题库中的题目对下级所有团队可见
+func AddOrganizationProblem(orgID uint64, problemID uint64, operatorID uint64) error {
+	if _, err := getOrganization(orgID); err != nil {
+		return err
+	}
+	if err := checkOrganizationAdmin(orgID, operatorID); err != nil {
+		return err
+	}
+
+	var problem models.Problem
+	if err := config.DB.Select("id").First(&problem, problemID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("题目不存在")
+		}
+		return err
+	}
+
+	result := config.DB.Exec(`
+		INSERT IGNORE INTO organization_problems (org_id, problem_id, added_by)
+		VALUES (?, ?, ?)
+	`, orgID, problemID, operatorID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("题目已在组织题库中")
+	}
+	return nil
+}
+
+// RemoveOrganizationProblem 将题目移出组织题库
+func RemoveOrganizationProblem(orgID uint64, problemID uint64, operatorID uint64) error {
+	if err := checkOrganizationAdmin(orgID, operatorID); err != nil {
+		return err
+	}
+
+	result := config.DB.Where("org_id = ? AND problem_id = ?", orgID, problemID).Delete(&models.OrganizationProblem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("题目不在组织题库中")
+	}
+	return nil
+}
+
+// GetOrganizationProblems 获取组织题库
+func GetOrganizationProblems(orgID uint64, userID uint64) ([]models.OrganizationProblemInfo, error) {
+	if _, err := getOrganization(orgID); err != nil {
+		return nil, err
+	}
+	allowed, err := canViewOrganization(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("权限不足")
+	}
+
+	problems := make([]models.OrganizationProblemInfo, 0)
+	if err := config.DB.Table("organization_problems op").
+		Select("op.problem_id, p.title, COALESCE(p.difficulty, 'medium') as difficulty, op.added_by, op.created_at").
+		Joins("JOIN problems p ON p.id = op.problem_id").
+		Where("op.org_id = ?", orgID).
+		Order("op.created_at DESC").
+		Scan(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// linkProfileSchool 将用户资料关联到学校组织，关联后需要组织管理员认证
+func linkProfileSchool(orgID uint64) (string, error) {
+	org, err := getOrganization(orgID)
+	if err != nil {
+		return "", err
+	}
+	if org.Type != models.OrganizationTypeSchool {
+		return "", errors.New("只能关联学校类型的组织")
+	}
+	return org.Name, nil
+}
+
+// GetSchoolMembers 获取关联到学校组织的用户，供组织管理员认证
+func GetSchoolMembers(orgID uint64, operatorID uint64) ([]models.SchoolMemberInfo, error) {
+	if err := checkOrganizationAdmin(orgID, operatorID); err != nil {
+		return nil, err
+	}
+
+	members := make([]models.SchoolMemberInfo, 0)
+	if err := config.DB.Table("profiles p").
+		Select("p.user_id, u.username, p.real_name, p.school_verified").
+		Joins("JOIN users u ON u.id = p.user_id").
+		Where("p.school_org_id = ?", orgID).
+		Order("p.school_verified, p.user_id").
+		Scan(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// VerifySchoolMember 组织管理员认证或取消认证用户的学校关联
+func VerifySchoolMember(orgID uint64, targetUserID uint64, verified bool, operatorID uint64) error {
+	if err := checkOrganizationAdmin(orgID, operatorID); err != nil {
+		return err
+	}
+
+	result := config.DB.Model(&models.Profile{}).
+		Where("user_id = ? AND school_org_id = ?", targetUserID, orgID).
+		Update("school_verified", verified)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := config.DB.Model(&models.Profile{}).
+			Where("user_id = ? AND school_org_id = ?", targetUserID, orgID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("该用户未关联此学校")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	passwordResetCodeTTL     = 15 * time.Minute
+	passwordResetCooldown    = time.Minute // 同一账号两次发送重置验证码的最小间隔
+	maxPasswordResetAttempts = 5           // 每个重置验证码最多尝试的次数
+)
+
+// ErrInvalidResetCode 重置验证码错误或已过期
+var ErrInvalidResetCode = errors.New("验证码无效或已过期")
+
+// SendPasswordResetCode 向账号绑定的邮箱或手机号发送重置验证码；账号不存在时不发送也不报错，避免泄露账号是否注册
+func SendPasswordResetCode(req *models.ForgotPasswordRequest) error {
+	ctx := context.Background()
+
+	// 发送间隔按提交的账号计算，与账号是否存在无关，避免通过频率限制的响应判断账号是否注册
+	ok, err := config.RedisClient.SetNX(ctx, passwordResetKey(req.AccountType, req.Account)+":cooldown", 1, passwordResetCooldown).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("请求过于频繁，请稍后再试")
+	}
+
+	user, err := findUserByAccount(req.AccountType, req.Account)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(900000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", n.Int64()+100000)
+
+	// 发送失败只记录日志，返回与账号不存在时相同的结果，避免通过错误信息判断账号是否注册
+	if req.AccountType == "email" {
+		body := fmt.Sprintf("您正在重置 OptiOJ 账号 %s 的密码，验证码是: %s，%d 分钟内有效。如非本人操作，请忽略此邮件。",
+			user.Username, code, int(passwordResetCodeTTL.Minutes()))
+		if err := SendEmail(req.Account, "重置密码", body); err != nil {
+			logrus.Errorf("向用户 %d 发送重置密码邮件失败: %v", user.ID, err)
+			return nil
+		}
+	} else {
+		if err := SendVerificationCodeToPhone(req.Account, code); err != nil {
+			logrus.Errorf("向用户 %d 发送重置密码短信失败: %v", user.ID, err)
+			return nil
+		}
+	}
+
+	// 新验证码生效后，旧验证码的错误次数清零
+	key := passwordResetKey(req.AccountType, req.Account)
+	if err := config.RedisClient.Set(ctx, key, code, passwordResetCodeTTL).Err(); err != nil {
+		return err
+	}
+	return config.RedisClient.Del(ctx, key+":attempts").Err()
+}
+
+// ResetPassword 校验重置验证码并设置新密码，成功后退出该账号的全部设备并吊销个人访问令牌；
+// 返回账号对应的用户ID（账号不存在时为 0），用于记录重置结果
+func ResetPassword(req *models.ResetPasswordRequest) (uint, error) {
+	ctx := context.Background()
+
+	user, err := findUserByAccount(req.AccountType, req.Account)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, ErrInvalidResetCode
+	}
+	userID := uint(user.ID)
+	key := passwordResetKey(req.AccountType, req.Account)
+
+	code, err := config.RedisClient.Get(ctx, key).Result()
+	if err != nil {
+		return userID, ErrInvalidResetCode
+	}
+
+	// 限制尝试次数，超过后验证码作废
+	attempts, err := config.RedisClient.Incr(ctx, key+":attempts").Result()
+	if err != nil {
+		return userID, err
+	}
+	config.RedisClient.Expire(ctx, key+":attempts", passwordResetCodeTTL)
+	if attempts > maxPasswordResetAttempts {
+		config.RedisClient.Del(ctx, key, key+":attempts")
+		return userID, errors.New("验证码错误次数过多，请重新获取")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(code), []byte(req.Code)) != 1 {
+		return userID, ErrInvalidResetCode
+	}
+
+	// 验证码只能使用一次，删除成功的请求才能继续重置
+	deleted, err := config.RedisClient.Del(ctx, key).Result()
+	if err != nil {
+		return userID, err
+	}
+	if deleted == 0 {
+		return userID, ErrInvalidResetCode
+	}
+	config.RedisClient.Del(ctx, key+":attempts")
+
+	hashedPassword, err := HashPassword(req.NewPassword)
+	if err != nil {
+		return userID, err
+	}
+	if err := config.DB.Model(user).Updates(map[string]interface{}{
+		"password":             hashedPassword,
+		"must_change_password": false,
+	}).Error; err != nil {
+		return userID, err
+	}
+	if err := config.RedisClient.Del(ctx, passwordChangeRequiredKey(userID)).Err(); err != nil {
+		return userID, err
+	}
+
+	// 重置密码后解除因登录失败导致的锁定
+	if err := ClearUserLoginLock(userID); err != nil {
+		return userID, err
+	}
+
+	// 重置密码后已登录的设备和个人访问令牌全部失效
+	if err := LogoutAllDevices(userID); err != nil {
+		return userID, err
+	}
+	if err := RevokeUserAccessTokens(userID); err != nil {
+		return userID, err
+	}
+	return userID, RevokeUserPersonalAccessTokens(userID)
+}
+
+// findUserByAccount 按邮箱或手机号查找用户，不存在时返回 nil
+func findUserByAccount(accountType string, account string) (*models.User, error) {
+	var user models.User
+	query := config.DB
+	if accountType == "email" {
+		query = query.Where("email = ?", account)
+	} else {
+		query = query.Where("phone = ?", account)
+	}
+
+	err := query.First(&user).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// passwordResetKey 重置验证码在 Redis 中的键
+func passwordResetKey(accountType string, account string) string {
+	return "password_reset:" + accountType + ":" + account
+}
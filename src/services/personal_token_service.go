@@ -0,0 +1,171 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// PersonalAccessTokenPrefix 个人访问令牌的固定前缀，用于和 JWT 区分
+	PersonalAccessTokenPrefix = "optioj_pat_"
+	maxPersonalAccessTokens   = 20          // 每个用户最多拥有的有效令牌数量
+	tokenLastUsedInterval     = time.Minute // 最近使用时间的更新间隔，避免每个请求都写数据库
+)
+
+// ErrTokenScopeDenied 个人访问令牌没有访问该接口的权限范围
+var ErrTokenScopeDenied = errors.New("令牌权限范围不足")
+
+// IsPersonalAccessToken 判断令牌是否为个人访问令牌
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// CreatePersonalAccessToken 创建个人访问令牌
+func CreatePersonalAccessToken(userID uint, req *models.CreatePersonalAccessTokenRequest) (*models.CreatePersonalAccessTokenResponse, error) {
+	var count int64
+	if err := activePersonalTokens(config.DB.Model(&models.PersonalAccessToken{})).
+		Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxPersonalAccessTokens {
+		return nil, errors.New("令牌数量已达上限，请先吊销不再使用的令牌")
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := PersonalAccessTokenPrefix + hex.EncodeToString(raw)
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !containsString(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	record := models.PersonalAccessToken{
+		UserID:      uint64(userID),
+		Name:        req.Name,
+		TokenHash:   hashPersonalAccessToken(token),
+		TokenPrefix: token[:len(PersonalAccessTokenPrefix)+4],
+		Scopes:      strings.Join(scopes, ","),
+		CreatedAt:   time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := record.CreatedAt.AddDate(0, 0, req.ExpiresInDays)
+		record.ExpiresAt = &expiresAt
+	}
+
+	if err := config.DB.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.CreatePersonalAccessTokenResponse{
+		Token: token,
+		Info:  toPersonalAccessTokenInfo(&record),
+	}, nil
+}
+
+// GetPersonalAccessTokens 获取用户未吊销且未过期的个人访问令牌
+func GetPersonalAccessTokens(userID uint) ([]models.PersonalAccessTokenInfo, error) {
+	var records []models.PersonalAccessToken
+	if err := activePersonalTokens(config.DB).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	tokens := make([]models.PersonalAccessTokenInfo, 0, len(records))
+	for i := range records {
+		tokens = append(tokens, toPersonalAccessTokenInfo(&records[i]))
+	}
+	return tokens, nil
+}
+
+// RevokePersonalAccessToken 吊销用户自己的个人访问令牌
+func RevokePersonalAccessToken(userID uint, tokenID uint64) error {
+	result := config.DB.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("令牌不存在")
+	}
+	return nil
+}
+
+// RevokeUserPersonalAccessTokens 吊销用户的全部个人访问令牌
+func RevokeUserPersonalAccessTokens(userID uint) error {
+	return config.DB.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ValidatePersonalAccessToken 验证个人访问令牌及其权限范围，并记录最近使用时间和IP
+func ValidatePersonalAccessToken(token string, scope string, ip string) (uint, error) {
+	var record models.PersonalAccessToken
+	err := activePersonalTokens(config.DB).
+		Where("token_hash = ?", hashPersonalAccessToken(token)).
+		First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, errors.New("令牌无效或已过期")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if !containsString(splitPermissions(record.Scopes), scope) {
+		return 0, ErrTokenScopeDenied
+	}
+	// 与访问令牌相同，需要修改密码的用户在修改密码前不能使用个人访问令牌
+	if err := checkPasswordChangeRequired(uint(record.UserID)); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= tokenLastUsedInterval {
+		config.DB.Model(&models.PersonalAccessToken{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		})
+	}
+
+	return uint(record.UserID), nil
+}
+
+// activePersonalTokens 过滤出未吊销且未过期的令牌
+func activePersonalTokens(db *gorm.DB) *gorm.DB {
+	return db.Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", time.Now())
+}
+
+// hashPersonalAccessToken 计算令牌摘要
+func hashPersonalAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// toPersonalAccessTokenInfo 转换为返回给用户的令牌信息
+func toPersonalAccessTokenInfo(record *models.PersonalAccessToken) models.PersonalAccessTokenInfo {
+	return models.PersonalAccessTokenInfo{
+		ID:          record.ID,
+		Name:        record.Name,
+		TokenPrefix: record.TokenPrefix,
+		Scopes:      splitPermissions(record.Scopes),
+		ExpiresAt:   record.ExpiresAt,
+		LastUsedAt:  record.LastUsedAt,
+		LastUsedIP:  record.LastUsedIP,
+		CreatedAt:   record.CreatedAt,
+	}
+}
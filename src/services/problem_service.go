@@ -289,8 +289,8 @@ func DeleteProblem(problemID uint64) error {
 	})
 }
 
-// GetProblemDetail 获取题目详情
-func GetProblemDetail(problemID uint64, userID uint64) (*models.ProblemDetail, error) {
+// GetProblemDetail 获取题目详情，canReadAll 表示当前请求拥有 problem.read 权限，由控制器根据请求上下文判断
+func GetProblemDetail(problemID uint64, userID uint64, canReadAll bool) (*models.ProblemDetail, error) {
 	var problem models.Problem
 	if err := config.DB.First(&problem, problemID).Error; err != nil {
 		return nil, err
@@ -298,12 +298,11 @@ func GetProblemDetail(problemID uint64, userID uint64) (*models.ProblemDetail, e
 
 	// 检查访问权限
 	if !problem.IsPublic {
-		// 如果题目不是公开的，只有管理员和题目创建者可以访问
+		// 如果题目不是公开的，只有拥有 problem.read 权限的用户和题目创建者可以访问
 		if userID == 0 {
 			return nil, errors.New("无权访问该题目")
 		}
-		isAdmin, _ := IsAdmin(uint(userID))
-		if !isAdmin && problem.CreatedBy != userID {
+		if !canReadAll && problem.CreatedBy != userID {
 			return nil, errors.New("无权访问该题目")
 		}
 	}
@@ -88,6 +88,16 @@ func UpdateProfile(userID uint, req *models.UpdateProfileRequest) error {
 		}
 	}
 
+	// 关联学校组织时，学校名称取组织名称，认证状态重置
+	var schoolOrgName string
+	if req.SchoolOrgID != nil {
+		name, err := linkProfileSchool(*req.SchoolOrgID)
+		if err != nil {
+			return err
+		}
+		schoolOrgName = name
+	}
+
 	// 处理地址信息
 	var locationStr string
 	if req.Province != "" || req.City != "" {
@@ -135,6 +145,10 @@ func UpdateProfile(userID uint, req *models.UpdateProfileRequest) error {
 		if req.RealName != "" {
 			profile.RealName = req.RealName
 		}
+		if req.SchoolOrgID != nil {
+			profile.School = schoolOrgName
+			profile.SchoolOrgID = req.SchoolOrgID
+		}
 		return config.DB.Create(&profile).Error
 	}
 
@@ -150,6 +164,18 @@ func UpdateProfile(userID uint, req *models.UpdateProfileRequest) error {
 	}
 	if req.School != "" {
 		updates["school"] = req.School
+		// 手动修改学校名称会解除学校组织关联
+		if profile.SchoolOrgID != nil && req.SchoolOrgID == nil && req.School != profile.School {
+			updates["school_org_id"] = nil
+			updates["school_verified"] = false
+		}
+	}
+	if req.SchoolOrgID != nil {
+		updates["school"] = schoolOrgName
+		if profile.SchoolOrgID == nil || *profile.SchoolOrgID != *req.SchoolOrgID {
+			updates["school_org_id"] = *req.SchoolOrgID
+			updates["school_verified"] = false
+		}
 	}
 	if !birthday.IsZero() {
 		updates["birthday"] = birthday
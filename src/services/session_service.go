@@ -5,14 +5,17 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
 
 	"OptiOJ/src/config"
+	"OptiOJ/src/models"
 )
 
 type DeviceInfo struct {
@@ -28,59 +31,104 @@ type SessionInfo struct {
 	CreatedAt  time.Time  `json:"created_at"`
 }
 
+// ErrSessionNotFound 会话不存在或不属于当前用户
+var ErrSessionNotFound = errors.New("会话不存在或不属于当前用户")
+
+// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用
+var ErrRefreshTokenReused = errors.New("刷新令牌已失效，检测到重复使用，相关会话已被注销")
+
 // 生成会话ID
 func generateSessionID(refreshToken string) string {
 	hash := sha256.Sum256([]byte(refreshToken))
 	return hex.EncodeToString(hash[:])
 }
 
-// GetActiveSessions 获取用户的所有活跃会话
+// userSessionsKey 用户会话索引，集合中保存该用户全部会话的会话ID
+func userSessionsKey(userID uint) string {
+	return fmt.Sprintf("user_sessions:%d", userID)
+}
+
+// userAccessTokensKey 用户访问令牌索引，集合中保存该用户签发过且可能仍有效的访问令牌
+func userAccessTokensKey(userID uint) string {
+	return fmt.Sprintf("user_access_tokens:%d", userID)
+}
+
+// SaveAccessToken 存储访问令牌（有效期2小时）并加入用户的访问令牌索引
+func SaveAccessToken(ctx context.Context, accessToken string, userID uint) error {
+	if err := config.RedisClient.Set(ctx, "access_token:"+accessToken, userID, 2*time.Hour).Err(); err != nil {
+		return err
+	}
+
+	indexKey := userAccessTokensKey(userID)
+	pipe := config.RedisClient.TxPipeline()
+	pipe.SAdd(ctx, indexKey, accessToken)
+	pipe.Expire(ctx, indexKey, 2*time.Hour)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RevokeUserAccessTokens 使用户当前所有的访问令牌立即失效
+func RevokeUserAccessTokens(userID uint) error {
+	ctx := context.Background()
+	indexKey := userAccessTokensKey(userID)
+
+	tokens, err := config.RedisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(tokens)+1)
+	for _, token := range tokens {
+		keys = append(keys, "access_token:"+token)
+	}
+	keys = append(keys, indexKey)
+	return config.RedisClient.Del(ctx, keys...).Err()
+}
+
+// getSessionRefreshToken 根据会话ID获取对应的刷新令牌，会话不存在时返回空字符串
+func getSessionRefreshToken(ctx context.Context, sessionID string) (string, error) {
+	refreshToken, err := config.RedisClient.Get(ctx, "session:"+sessionID).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return refreshToken, err
+}
+
+// GetActiveSessions 获取用户的所有活跃会话，顺带清理索引中已过期的会话
 func GetActiveSessions(userID uint) ([]SessionInfo, error) {
 	ctx := context.Background()
-	pattern := "refresh_token:*"
+	indexKey := userSessionsKey(userID)
 
-	var cursor uint64
-	var sessions []SessionInfo
+	sessionIDs, err := config.RedisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		var keys []string
-		var err error
-		keys, cursor, err = config.RedisClient.Scan(ctx, cursor, pattern, 10).Result()
+	sessions := make([]SessionInfo, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		refreshToken, err := getSessionRefreshToken(ctx, sessionID)
 		if err != nil {
 			return nil, err
 		}
+		if refreshToken == "" {
+			config.RedisClient.SRem(ctx, indexKey, sessionID)
+			continue
+		}
 
-		// 遍历找到的键
-		for _, key := range keys {
-			val, err := config.RedisClient.Get(ctx, key).Result()
-			if err != nil {
+		infoStr, err := config.RedisClient.Get(ctx, "session_info:"+refreshToken).Result()
+		if err != nil {
+			if err == redis.Nil {
+				config.RedisClient.SRem(ctx, indexKey, sessionID)
 				continue
 			}
-
-			// 检查是否属于当前用户
-			if val == strconv.FormatUint(uint64(userID), 10) {
-				refreshToken := strings.TrimPrefix(key, "refresh_token:")
-				// 获取会话信息
-				infoKey := "session_info:" + refreshToken
-				infoStr, err := config.RedisClient.Get(ctx, infoKey).Result()
-				if err != nil {
-					if err != redis.Nil {
-						continue
-					}
-					continue
-				}
-
-				var sessionInfo SessionInfo
-				if err := json.Unmarshal([]byte(infoStr), &sessionInfo); err != nil {
-					continue
-				}
-				sessions = append(sessions, sessionInfo)
-			}
+			return nil, err
 		}
 
-		if cursor == 0 {
-			break
+		var sessionInfo SessionInfo
+		if err := json.Unmarshal([]byte(infoStr), &sessionInfo); err != nil {
+			continue
 		}
+		sessions = append(sessions, sessionInfo)
 	}
 
 	return sessions, nil
@@ -114,27 +162,39 @@ func SaveSessionInfo(c *gin.Context, refreshToken string, userID uint) error {
 
 	// 存储会话ID到refreshToken的映射
 	sessionKey := "session:" + sessionInfo.SessionID
-	return config.RedisClient.Set(c, sessionKey, refreshToken, 30*24*time.Hour).Err()
+	if err := config.RedisClient.Set(c, sessionKey, refreshToken, 30*24*time.Hour).Err(); err != nil {
+		return err
+	}
+
+	// 加入用户会话索引，索引的有效期与最新的会话保持一致
+	indexKey := userSessionsKey(userID)
+	pipe := config.RedisClient.TxPipeline()
+	pipe.SAdd(c, indexKey, sessionInfo.SessionID)
+	pipe.Expire(c, indexKey, 30*24*time.Hour)
+	_, err = pipe.Exec(c)
+	return err
 }
 
-// RevokeSession 吊销指定会话
-func RevokeSession(sessionID string) error {
+// RevokeSession 吊销用户的指定会话，会话不属于该用户时返回 ErrSessionNotFound
+func RevokeSession(userID uint, sessionID string) error {
 	ctx := context.Background()
 
-	// 获取refreshToken
-	sessionKey := "session:" + sessionID
-	refreshToken, err := config.RedisClient.Get(ctx, sessionKey).Result()
+	owned, err := config.RedisClient.SIsMember(ctx, userSessionsKey(userID), sessionID).Result()
 	if err != nil {
-		if err == redis.Nil {
-			return nil // 会话已经不存在
-		}
 		return err
 	}
+	if !owned {
+		return ErrSessionNotFound
+	}
 
-	// 删除会话ID映射
-	if err := config.RedisClient.Del(ctx, sessionKey).Err(); err != nil {
+	refreshToken, err := getSessionRefreshToken(ctx, sessionID)
+	if err != nil {
 		return err
 	}
+	if refreshToken == "" {
+		// 会话已经过期，只需清理索引
+		return config.RedisClient.SRem(ctx, userSessionsKey(userID), sessionID).Err()
+	}
 
 	// 调用Logout删除相关信息
 	return Logout(refreshToken)
@@ -165,37 +225,17 @@ func UpdateSessionLastActive(c *gin.Context, refreshToken string) error {
 	return config.RedisClient.Set(ctx, infoKey, string(infoBytes), 30*24*time.Hour).Err()
 }
 
-// UpdateSessionLastRefresh 更新会话最后刷新时间和设备信息
-func UpdateSessionLastRefresh(c *gin.Context, refreshToken string) error {
+// Logout 退出登录
+func Logout(refreshToken string) error {
 	ctx := context.Background()
-	infoKey := "session_info:" + refreshToken
-
-	infoStr, err := config.RedisClient.Get(ctx, infoKey).Result()
-	if err != nil {
-		return err
-	}
-
-	var sessionInfo SessionInfo
-	if err := json.Unmarshal([]byte(infoStr), &sessionInfo); err != nil {
-		return err
-	}
-
-	sessionInfo.DeviceInfo.LastRefresh = time.Now()
-	sessionInfo.DeviceInfo.UserAgent = c.GetHeader("User-Agent")
-	sessionInfo.DeviceInfo.IP = c.ClientIP()
 
-	infoBytes, err := json.Marshal(sessionInfo)
-	if err != nil {
+	// 获取令牌所属用户，用于维护用户会话索引
+	refreshSessionKey := "refresh_token:" + refreshToken
+	userIDStr, err := config.RedisClient.Get(ctx, refreshSessionKey).Result()
+	if err != nil && err != redis.Nil {
 		return err
 	}
 
-	return config.RedisClient.Set(ctx, infoKey, string(infoBytes), 30*24*time.Hour).Err()
-}
-
-// Logout 退出登录
-func Logout(refreshToken string) error {
-	ctx := context.Background()
-
 	// 获取会话信息以获取sessionID
 	infoKey := "session_info:" + refreshToken
 	infoStr, err := config.RedisClient.Get(ctx, infoKey).Result()
@@ -213,6 +253,12 @@ func Logout(refreshToken string) error {
 		if err := config.RedisClient.Del(ctx, sessionKey).Err(); err != nil {
 			return err
 		}
+		// 从用户会话索引中移除
+		if userID, err := strconv.ParseUint(userIDStr, 10, 64); err == nil {
+			if err := config.RedisClient.SRem(ctx, userSessionsKey(uint(userID)), sessionInfo.SessionID).Err(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// 删除会话信息
@@ -221,7 +267,6 @@ func Logout(refreshToken string) error {
 	}
 
 	// 删除刷新令牌
-	refreshSessionKey := "refresh_token:" + refreshToken
 	if err := config.RedisClient.Del(ctx, refreshSessionKey).Err(); err != nil {
 		return err
 	}
@@ -232,35 +277,146 @@ func Logout(refreshToken string) error {
 // LogoutAllDevices 退出所有设备
 func LogoutAllDevices(userID uint) error {
 	ctx := context.Background()
-	pattern := "refresh_token:*"
+	indexKey := userSessionsKey(userID)
+
+	sessionIDs, err := config.RedisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return err
+	}
 
-	var cursor uint64
-	for {
-		var keys []string
-		var err error
-		keys, cursor, err = config.RedisClient.Scan(ctx, cursor, pattern, 10).Result()
+	for _, sessionID := range sessionIDs {
+		refreshToken, err := getSessionRefreshToken(ctx, sessionID)
 		if err != nil {
 			return err
 		}
+		if refreshToken == "" {
+			continue
+		}
+		if err := Logout(refreshToken); err != nil {
+			return err
+		}
+	}
 
-		for _, key := range keys {
-			val, err := config.RedisClient.Get(ctx, key).Result()
-			if err != nil {
-				continue
-			}
+	return config.RedisClient.Del(ctx, indexKey).Err()
+}
 
-			if val == strconv.FormatUint(uint64(userID), 10) {
-				refreshToken := strings.TrimPrefix(key, "refresh_token:")
-				if err := Logout(refreshToken); err != nil {
-					return err
-				}
-			}
+// RotateRefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌立即失效。
+// 同一会话中轮换出的刷新令牌属于同一个令牌族，会话ID即令牌族ID；
+// 已被轮换的刷新令牌再次使用时视为令牌泄露，吊销整个令牌族并提醒用户
+func RotateRefreshToken(c *gin.Context, refreshToken string) (string, string, error) {
+	ctx := context.Background()
+
+	userID, err := ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	infoKey := "session_info:" + refreshToken
+	infoStr, err := config.RedisClient.Get(ctx, infoKey).Result()
+	if err != nil && err != redis.Nil {
+		return "", "", err
+	}
+
+	var sessionInfo SessionInfo
+	if infoStr != "" {
+		if err := json.Unmarshal([]byte(infoStr), &sessionInfo); err != nil {
+			return "", "", err
 		}
+	}
 
-		if cursor == 0 {
-			break
+	// 以删除旧令牌成功作为本次轮换的凭据，并发请求中只有一个能够成功；
+	// 轮换记录与删除在同一事务中写入，并发重放的请求同样会被识别为复用
+	pipe := config.RedisClient.TxPipeline()
+	if sessionInfo.SessionID != "" {
+		pipe.Set(ctx, rotatedRefreshTokenKey(refreshToken), sessionInfo.SessionID, 30*24*time.Hour)
+	}
+	delCmd := pipe.Del(ctx, "refresh_token:"+refreshToken)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", "", err
+	}
+	if delCmd.Val() == 0 || infoStr == "" {
+		familyID, err := config.RedisClient.Get(ctx, rotatedRefreshTokenKey(refreshToken)).Result()
+		if err == nil {
+			revokeRefreshTokenFamily(c, userID, familyID)
+			return "", "", ErrRefreshTokenReused
+		}
+		if err != redis.Nil {
+			return "", "", err
 		}
+		return "", "", errors.New("刷新令牌已失效")
 	}
 
-	return nil
+	sessionInfo.DeviceInfo.LastRefresh = time.Now()
+	sessionInfo.DeviceInfo.UserAgent = c.GetHeader("User-Agent")
+	sessionInfo.DeviceInfo.IP = c.ClientIP()
+	infoBytes, err := json.Marshal(sessionInfo)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, newRefreshToken, err := GenerateTokenPair(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if err := SaveAccessToken(ctx, accessToken, userID); err != nil {
+		return "", "", err
+	}
+
+	indexKey := userSessionsKey(userID)
+	pipe = config.RedisClient.TxPipeline()
+	pipe.Set(ctx, "refresh_token:"+newRefreshToken, userID, 30*24*time.Hour)
+	pipe.Set(ctx, "session_info:"+newRefreshToken, string(infoBytes), 30*24*time.Hour)
+	pipe.Set(ctx, "session:"+sessionInfo.SessionID, newRefreshToken, 30*24*time.Hour)
+	pipe.SAdd(ctx, indexKey, sessionInfo.SessionID)
+	pipe.Expire(ctx, indexKey, 30*24*time.Hour)
+	pipe.Del(ctx, infoKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", "", err
+	}
+
+	return accessToken, newRefreshToken, nil
+}
+
+// rotatedRefreshTokenKey 已轮换的刷新令牌记录，值为所属令牌族（会话）ID，保留到令牌自然过期
+func rotatedRefreshTokenKey(refreshToken string) string {
+	return "refresh_token_rotated:" + refreshToken
+}
+
+// revokeRefreshTokenFamily 吊销令牌族当前有效的刷新令牌和用户已签发的访问令牌，并通过站内信和邮件提醒用户；
+// 访问令牌没有按令牌族索引，因此全部吊销，其他设备可以用各自的刷新令牌重新换取
+func revokeRefreshTokenFamily(c *gin.Context, userID uint, familyID string) {
+	ctx := context.Background()
+
+	currentToken, err := getSessionRefreshToken(ctx, familyID)
+	if err != nil {
+		logrus.Errorf("获取会话 %s 的刷新令牌失败: %v", familyID, err)
+	} else if currentToken != "" {
+		if err := Logout(currentToken); err != nil {
+			logrus.Errorf("吊销会话 %s 失败: %v", familyID, err)
+		}
+	}
+	config.RedisClient.SRem(ctx, userSessionsKey(userID), familyID)
+	if err := RevokeUserAccessTokens(userID); err != nil {
+		logrus.Errorf("吊销用户 %d 的访问令牌失败: %v", userID, err)
+	}
+
+	title := "账号安全提醒"
+	content := fmt.Sprintf(
+		"检测到一个已失效的登录凭证于 %s 被再次使用（IP：%s，设备：%s），该凭证可能已泄露。"+
+			"为保护账号安全，对应设备的登录状态已被注销，其他设备需要重新获取访问令牌。如非本人操作，请尽快修改密码并检查活跃会话。",
+		time.Now().Format("2006-01-02 15:04:05"), c.ClientIP(), c.GetHeader("User-Agent"),
+	)
+	if err := CreateMessage(nil, uint64(userID), models.MessageTypeSystem, title, content); err != nil {
+		logrus.Errorf("发送令牌复用提醒站内信失败: %v", err)
+	}
+
+	var user models.User
+	if err := config.DB.Select("email").First(&user, userID).Error; err != nil || user.Email == "" {
+		return
+	}
+	go func(email string) {
+		if err := SendEmail(email, "OptiOJ "+title, content); err != nil {
+			logrus.Errorf("发送令牌复用提醒邮件失败: %v", err)
+		}
+	}(user.Email)
 }
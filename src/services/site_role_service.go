@@ -0,0 +1,254 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 自定义网站角色标识，规则与团队角色相同
+var siteRolePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+
+// GetUserSitePermissions 获取用户在网站范围内生效的全部权限：管理员角色与分配的角色权限取并集
+func GetUserSitePermissions(userID uint) ([]string, error) {
+	roles, err := getUserSiteRoleNames(uint64(userID))
+	if err != nil {
+		return nil, err
+	}
+	adminRole, err := GetAdminRole(userID)
+	if err != nil {
+		return nil, err
+	}
+	if adminRole != "" {
+		roles = append(roles, adminRole)
+	}
+	if len(roles) == 0 {
+		return []string{}, nil
+	}
+
+	permissionMap, err := getSiteRolePermissionMap()
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]string, 0)
+	for _, permission := range models.AllSitePermissions {
+		for _, role := range roles {
+			if containsString(permissionMap[role], permission) {
+				permissions = append(permissions, permission)
+				break
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// HasSitePermission 判断用户是否拥有指定的网站权限
+func HasSitePermission(userID uint64, permission string) (bool, error) {
+	permissions, err := GetUserSitePermissions(uint(userID))
+	if err != nil {
+		return false, err
+	}
+	return containsString(permissions, permission), nil
+}
+
+// getUserSiteRoleNames 获取用户通过角色分配获得的角色
+func getUserSiteRoleNames(userID uint64) ([]string, error) {
+	roles := make([]string, 0)
+	err := config.DB.Model(&models.UserSiteRole{}).
+		Where("user_id = ?", userID).
+		Order("role").
+		Pluck("role", &roles).Error
+	return roles, err
+}
+
+// getSiteRolePermissionMap 获取各网站角色的权限，自定义配置覆盖内置角色的默认权限
+func getSiteRolePermissionMap() (map[string][]string, error) {
+	roles, err := GetSiteRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		result[role.Role] = role.Permissions
+	}
+	return result, nil
+}
+
+// GetSiteRoles 获取全部网站角色（内置角色在前，自定义角色在后）
+func GetSiteRoles() ([]models.SiteRoleInfo, error) {
+	var custom []models.SiteRole
+	if err := config.DB.Order("role").Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]models.SiteRole, len(custom))
+	for _, role := range custom {
+		overrides[role.Role] = role
+	}
+
+	roles := make([]models.SiteRoleInfo, 0, len(models.DefaultSiteRoles)+len(custom))
+	for _, role := range models.DefaultSiteRoles {
+		if override, ok := overrides[role.Role]; ok && role.Role != models.SiteRoleSuperAdmin {
+			role.Name = override.Name
+			role.Permissions = splitPermissions(override.Permissions)
+			delete(overrides, role.Role)
+		}
+		roles = append(roles, role)
+	}
+	for _, role := range custom {
+		if _, ok := overrides[role.Role]; !ok || isBuiltInSiteRole(role.Role) {
+			continue
+		}
+		roles = append(roles, models.SiteRoleInfo{
+			Role:        role.Role,
+			Name:        role.Name,
+			Permissions: splitPermissions(role.Permissions),
+		})
+	}
+
+	return roles, nil
+}
+
+// isBuiltInSiteRole 判断是否为内置网站角色
+func isBuiltInSiteRole(role string) bool {
+	for _, r := range models.DefaultSiteRoles {
+		if r.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// siteRoleExists 判断网站角色是否存在
+func siteRoleExists(role string) (bool, error) {
+	if isBuiltInSiteRole(role) {
+		return true, nil
+	}
+	var count int64
+	if err := config.DB.Model(&models.SiteRole{}).Where("role = ?", role).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SaveSiteRole 创建自定义网站角色或修改角色权限，超级管理员角色不能修改
+func SaveSiteRole(role string, req *models.SaveSiteRoleRequest) error {
+	if role == models.SiteRoleSuperAdmin {
+		return errors.New("不能修改超级管理员角色")
+	}
+	if !siteRolePattern.MatchString(role) {
+		return errors.New("无效的角色标识")
+	}
+
+	return config.DB.Exec(`
+		INSERT INTO site_roles (role, name, permissions)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		name = VALUES(name),
+		permissions = VALUES(permissions),
+		updated_at = CURRENT_TIMESTAMP
+	`, role, req.Name, strings.Join(req.Permissions, ",")).Error
+}
+
+// DeleteSiteRole 删除自定义网站角色；对内置角色则恢复默认权限
+func DeleteSiteRole(role string) error {
+	// 自定义角色仍分配给用户时不能删除
+	if !isBuiltInSiteRole(role) {
+		var count int64
+		if err := config.DB.Model(&models.UserSiteRole{}).Where("role = ?", role).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("该角色仍分配给用户，无法删除")
+		}
+	}
+
+	result := config.DB.Where("role = ?", role).Delete(&models.SiteRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("角色不存在或未修改过")
+	}
+	return nil
+}
+
+// GetUserSiteRoles 获取用户的网站角色和生效的权限
+func GetUserSiteRoles(userID uint64) (*models.UserSiteRolesResponse, error) {
+	var user models.User
+	if err := config.DB.Select("id").First(&user, userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	roles, err := getUserSiteRoleNames(userID)
+	if err != nil {
+		return nil, err
+	}
+	adminRole, err := GetAdminRole(uint(userID))
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := GetUserSitePermissions(uint(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserSiteRolesResponse{
+		UserID:      userID,
+		AdminRole:   adminRole,
+		Roles:       roles,
+		Permissions: permissions,
+	}, nil
+}
+
+// SetUserSiteRoles 设置用户的网站角色，替换用户现有的角色分配。
+// 超级管理员和管理员角色仍通过管理员接口设置，不能在这里分配
+func SetUserSiteRoles(userID uint64, req *models.SetUserSiteRolesRequest, operatorID uint64) error {
+	var user models.User
+	if err := config.DB.Select("id").First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+
+	roles := make([]string, 0, len(req.Roles))
+	for _, role := range req.Roles {
+		if role == models.SiteRoleSuperAdmin || role == models.SiteRoleAdmin {
+			return errors.New("管理员角色请通过管理员接口设置")
+		}
+		if containsString(roles, role) {
+			continue
+		}
+		exists, err := siteRoleExists(role)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("角色不存在：" + role)
+		}
+		roles = append(roles, role)
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserSiteRole{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, role := range roles {
+			assignment := models.UserSiteRole{
+				UserID:    userID,
+				Role:      role,
+				GrantedBy: operatorID,
+				CreatedAt: now,
+			}
+			if err := tx.Create(&assignment).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTeamStatsWeeks = 8  // 默认统计最近 8 周
+	weakestTagLimit       = 5  // 返回通过率最低的标签数量
+	minTagSubmissions     = 10 // 标签提交数少于该值时不参与薄弱标签统计
+)
+
+// recordTeamEvent 记录团队动态，在事务中调用时与业务数据一起提交
+func recordTeamEvent(tx *gorm.DB, teamID uint64, actorID *uint64, eventType string, targetID uint64, summary string) error {
+	event := &models.TeamEvent{
+		TeamID:    teamID,
+		ActorID:   actorID,
+		Type:      eventType,
+		TargetID:  &targetID,
+		Summary:   summary,
+		CreatedAt: time.Now(),
+	}
+	return tx.Create(event).Error
+}
+
+// logTeamEvent 记录团队动态，用于业务已完成的场景，失败时只记录日志
+func logTeamEvent(teamID uint64, actorID *uint64, eventType string, targetID uint64, summary string) {
+	if err := recordTeamEvent(config.DB, teamID, actorID, eventType, targetID, summary); err != nil {
+		logrus.Errorf("记录团队 %d 动态失败: %v", teamID, err)
+	}
+}
+
+// recordAssignmentAcceptedEvent 作业提交判题通过时记录动态，同一成员同一题目只记录首次通过
+func recordAssignmentAcceptedEvent(submission *models.Submission) {
+	if submission.AssignmentID == nil {
+		return
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.Submission{}).
+		Where("assignment_id = ? AND problem_id = ? AND user_id = ? AND status = ? AND id <> ?",
+			*submission.AssignmentID, submission.ProblemID, submission.UserID, models.StatusAccepted, submission.ID).
+		Count(&count).Error; err != nil {
+		logrus.Errorf("查询提交 %d 的通过记录失败: %v", submission.ID, err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	var assignment models.TeamAssignment
+	if err := config.DB.Select("id, team_id, title").First(&assignment, *submission.AssignmentID).Error; err != nil {
+		logrus.Errorf("获取提交 %d 的作业失败: %v", submission.ID, err)
+		return
+	}
+	logTeamEvent(assignment.TeamID, &submission.UserID, models.TeamEventAccepted, submission.ID,
+		"通过了作业 "+assignment.Title+" 中的一道题目")
+}
+
+// GetTeamEvents 获取团队动态。没有查看提交权限的成员看不到其他成员的提交和通过动态
+func GetTeamEvents(teamID uint64, req *models.TeamEventListRequest, userID uint64) (*models.TeamEventListResponse, error) {
+	if _, err := AuthorizeTeam(teamID, userID, ""); err != nil {
+		return nil, err
+	}
+	canViewSubmissions, err := HasTeamPermission(teamID, userID, models.TeamPermViewSubmissions)
+	if err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Table("team_events e").Where("e.team_id = ?", teamID)
+	if req.Type != "" {
+		query = query.Where("e.type = ?", req.Type)
+	}
+	if !canViewSubmissions {
+		query = query.Where("(e.type NOT IN ? OR e.actor_id = ?)",
+			[]string{models.TeamEventSubmission, models.TeamEventAccepted}, userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]models.TeamEventInfo, 0)
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Select("e.*, COALESCE(tn.nickname, u.username, '') as actor_name").
+		Joins("LEFT JOIN users u ON u.id = e.actor_id").
+		Joins("LEFT JOIN team_nicknames tn ON tn.team_id = e.team_id AND tn.user_id = e.actor_id").
+		Order("e.created_at DESC, e.id DESC").
+		Offset(offset).Limit(req.PageSize).
+		Scan(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.TeamEventListResponse{
+		Events:   events,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+// weekStart 获取时间所在周的周一零点
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// GetTeamStats 获取团队统计面板：每周活跃成员和提交量、各作业通过率以及通过率最低的标签。
+// 每周统计和标签统计基于当前成员的全部提交
+func GetTeamStats(teamID uint64, req *models.TeamStatsRequest, userID uint64) (*models.TeamStatsResponse, error) {
+	if _, err := AuthorizeTeam(teamID, userID, models.TeamPermViewSubmissions); err != nil {
+		return nil, err
+	}
+
+	weeks := req.Weeks
+	if weeks == 0 {
+		weeks = defaultTeamStatsWeeks
+	}
+	since := weekStart(time.Now()).AddDate(0, 0, -7*(weeks-1))
+
+	var memberIDs []uint64
+	if err := config.DB.Model(&models.TeamMember{}).
+		Where("team_id = ?", teamID).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, err
+	}
+
+	response := &models.TeamStatsResponse{
+		MemberCount: len(memberIDs),
+		Weeks:       make([]models.TeamWeeklyStats, weeks),
+		Assignments: make([]models.TeamAssignmentStats, 0),
+		WeakestTags: make([]models.TeamTagStats, 0),
+	}
+	for i := range response.Weeks {
+		response.Weeks[i].WeekStart = since.AddDate(0, 0, 7*i)
+	}
+
+	if len(memberIDs) > 0 {
+		if err := fillTeamWeeklyStats(response.Weeks, memberIDs, since); err != nil {
+			return nil, err
+		}
+		tags, err := getTeamWeakestTags(memberIDs, since)
+		if err != nil {
+			return nil, err
+		}
+		response.WeakestTags = tags
+	}
+
+	assignments, err := getTeamAssignmentStats(teamID)
+	if err != nil {
+		return nil, err
+	}
+	response.Assignments = assignments
+
+	return response, nil
+}
+
+// fillTeamWeeklyStats 按天汇总成员提交后归入所在的周
+func fillTeamWeeklyStats(weeks []models.TeamWeeklyStats, memberIDs []uint64, since time.Time) error {
+	var rows []struct {
+		UserID   uint64
+		Day      time.Time
+		Total    int
+		Accepted int
+	}
+	if err := config.DB.Table("submissions").
+		Select("user_id, DATE(created_at) as day, COUNT(*) as total, SUM(status = ?) as accepted", models.StatusAccepted).
+		Where("user_id IN ? AND created_at >= ?", memberIDs, since).
+		Group("user_id, DATE(created_at)").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	active := make([]map[uint64]bool, len(weeks))
+	for _, row := range rows {
+		day := time.Date(row.Day.Year(), row.Day.Month(), row.Day.Day(), 0, 0, 0, 0, since.Location())
+		// 按天数取整，避免夏令时导致的小时偏差
+		index := int(math.Round(day.Sub(since).Hours()/24)) / 7
+		if index < 0 || index >= len(weeks) {
+			continue
+		}
+		weeks[index].Submissions += row.Total
+		weeks[index].Accepted += row.Accepted
+		if active[index] == nil {
+			active[index] = make(map[uint64]bool)
+		}
+		active[index][row.UserID] = true
+	}
+	for i := range weeks {
+		weeks[i].ActiveMembers = len(active[i])
+	}
+	return nil
+}
+
+// getTeamAssignmentStats 统计团队已发布作业的提交量和通过率
+func getTeamAssignmentStats(teamID uint64) ([]models.TeamAssignmentStats, error) {
+	stats := make([]models.TeamAssignmentStats, 0)
+	if err := config.DB.Table("team_assignments a").
+		Select(`
+			a.id as assignment_id,
+			a.title,
+			COUNT(DISTINCT s.user_id) as participants,
+			COUNT(s.id) as submissions,
+			COALESCE(SUM(s.status = ?), 0) as accepted
+		`, models.StatusAccepted).
+		Joins("LEFT JOIN submissions s ON s.assignment_id = a.id").
+		Where("a.team_id = ? AND a.is_published = ?", teamID, true).
+		Group("a.id, a.title, a.start_time").
+		Order("a.start_time DESC").
+		Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range stats {
+		stats[i].ACRate = completionRate(stats[i].Accepted, stats[i].Submissions)
+	}
+	return stats, nil
+}
+
+// getTeamWeakestTags 统计成员提交涉及的标签，返回通过率最低的若干个
+func getTeamWeakestTags(memberIDs []uint64, since time.Time) ([]models.TeamTagStats, error) {
+	tags := make([]models.TeamTagStats, 0)
+	if err := config.DB.Table("submissions s").
+		Select("t.id as tag_id, t.name, COUNT(*) as submissions, SUM(s.status = ?) as accepted", models.StatusAccepted).
+		Joins("JOIN problem_tag_relations r ON r.problem_id = s.problem_id").
+		Joins("JOIN problem_tags t ON t.id = r.tag_id").
+		Where("s.user_id IN ? AND s.created_at >= ?", memberIDs, since).
+		Group("t.id, t.name").
+		Having("COUNT(*) >= ?", minTagSubmissions).
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range tags {
+		tags[i].ACRate = completionRate(tags[i].Accepted, tags[i].Submissions)
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].ACRate != tags[j].ACRate {
+			return tags[i].ACRate < tags[j].ACRate
+		}
+		return tags[i].Submissions > tags[j].Submissions
+	})
+	if len(tags) > weakestTagLimit {
+		tags = tags[:weakestTagLimit]
+	}
+	return tags, nil
+}
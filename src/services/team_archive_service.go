@@ -0,0 +1,262 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// checkTeamWritable 检查团队是否可以修改，已归档的团队只读
+func checkTeamWritable(teamID uint64) error {
+	var team models.Team
+	if err := config.DB.Select("id, archived_at").First(&team, teamID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("团队不存在")
+		}
+		return err
+	}
+	if team.ArchivedAt != nil {
+		return errors.New("团队已归档，无法修改")
+	}
+	return nil
+}
+
+// ArchiveTeam 归档团队，归档后团队只读，提交记录和成绩表保留；团队所有者或组织管理员可以操作
+func ArchiveTeam(teamID uint64, userID uint64) error {
+	role, err := AuthorizeTeam(teamID, userID, "")
+	if err != nil {
+		return err
+	}
+	if role != models.TeamRoleOwner && role != models.TeamRoleOrgAdmin {
+		return errors.New("只有团队所有者或组织管理员可以归档团队")
+	}
+	if err := checkTeamWritable(teamID); err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// 取消待确认的所有权转让
+		if err := tx.Model(&models.TeamOwnershipTransfer{}).
+			Where("team_id = ? AND status = ?", teamID, models.OwnershipTransferPending).
+			Update("status", models.OwnershipTransferCancelled).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&models.Team{}).Where("id = ?", teamID).Updates(map[string]interface{}{
+			"archived_at": now,
+			"archived_by": userID,
+			"updated_at":  now,
+		}).Error
+	})
+}
+
+// RestoreTeam 恢复已归档的团队，团队所有者、组织管理员或网站管理员可以操作
+func RestoreTeam(teamID uint64, userID uint64) error {
+	var team models.Team
+	if err := config.DB.First(&team, teamID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("团队不存在")
+		}
+		return err
+	}
+	if team.ArchivedAt == nil {
+		return errors.New("团队未归档")
+	}
+
+	role, err := getTeamEffectiveRole(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if role != models.TeamRoleOwner && role != models.TeamRoleOrgAdmin {
+		isAdmin, err := HasSitePermission(userID, models.SitePermOrgManage)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return errors.New("只有团队所有者、组织管理员或网站管理员可以恢复团队")
+		}
+	}
+
+	return config.DB.Model(&models.Team{}).Where("id = ?", teamID).Updates(map[string]interface{}{
+		"archived_at": nil,
+		"archived_by": nil,
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+// TransferTeamOwnership 发起团队所有权转让，需要接收方确认后生效
+func TransferTeamOwnership(teamID uint64, req *models.TransferTeamOwnershipRequest, operatorID uint64) (*models.TeamOwnershipTransfer, error) {
+	role, err := AuthorizeTeam(teamID, operatorID, "")
+	if err != nil {
+		return nil, err
+	}
+	if role != models.TeamRoleOwner {
+		return nil, errors.New("只有团队所有者可以转让团队")
+	}
+	if err := checkTeamWritable(teamID); err != nil {
+		return nil, err
+	}
+
+	if req.UserID == operatorID {
+		return nil, errors.New("不能将团队转让给自己")
+	}
+	isMember, err := IsTeamMember(teamID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errors.New("只能将团队转让给团队成员")
+	}
+
+	var team models.Team
+	if err := config.DB.First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+
+	transfer := &models.TeamOwnershipTransfer{
+		TeamID:     teamID,
+		FromUserID: operatorID,
+		ToUserID:   req.UserID,
+		Status:     models.OwnershipTransferPending,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// 同一团队只保留一个待确认的转让
+		if err := tx.Model(&models.TeamOwnershipTransfer{}).
+			Where("team_id = ? AND status = ?", teamID, models.OwnershipTransferPending).
+			Update("status", models.OwnershipTransferCancelled).Error; err != nil {
+			return err
+		}
+		return tx.Create(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 通知接收方
+	content := fmt.Sprintf("团队 %s 的所有者希望将团队转让给您，请在团队转让中确认（转让编号：%d）", team.Name, transfer.ID)
+	if err := CreateMessage(&operatorID, req.UserID, models.MessageTypeTeamNotice, "团队转让请求 - "+team.Name, content); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// CancelOwnershipTransfer 取消待确认的所有权转让
+func CancelOwnershipTransfer(teamID uint64, operatorID uint64) error {
+	role, err := AuthorizeTeam(teamID, operatorID, "")
+	if err != nil {
+		return err
+	}
+	if role != models.TeamRoleOwner {
+		return errors.New("权限不足")
+	}
+
+	result := config.DB.Model(&models.TeamOwnershipTransfer{}).
+		Where("team_id = ? AND status = ?", teamID, models.OwnershipTransferPending).
+		Updates(map[string]interface{}{
+			"status":     models.OwnershipTransferCancelled,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("没有待确认的转让")
+	}
+	return nil
+}
+
+// GetPendingOwnershipTransfers 获取等待当前用户确认的所有权转让
+func GetPendingOwnershipTransfers(userID uint64) ([]models.TeamOwnershipTransfer, error) {
+	var transfers []models.TeamOwnershipTransfer
+	if err := config.DB.Where("to_user_id = ? AND status = ?", userID, models.OwnershipTransferPending).
+		Order("created_at DESC").
+		Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// RespondOwnershipTransfer 接收方确认或拒绝所有权转让
+func RespondOwnershipTransfer(transferID uint64, accept bool, userID uint64) error {
+	var transfer models.TeamOwnershipTransfer
+	if err := config.DB.First(&transfer, transferID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("转让不存在")
+		}
+		return err
+	}
+	if transfer.ToUserID != userID {
+		return errors.New("权限不足")
+	}
+	if transfer.Status != models.OwnershipTransferPending {
+		return errors.New("该转让已被处理")
+	}
+
+	var team models.Team
+	if err := config.DB.First(&team, transfer.TeamID).Error; err != nil {
+		return err
+	}
+
+	if !accept {
+		if err := config.DB.Model(&transfer).Updates(map[string]interface{}{
+			"status":     models.OwnershipTransferRejected,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return CreateMessage(&userID, transfer.FromUserID, models.MessageTypeTeamNotice,
+			"团队转让被拒绝 - "+team.Name, fmt.Sprintf("您对团队 %s 的转让请求已被拒绝", team.Name))
+	}
+
+	if err := checkTeamWritable(transfer.TeamID); err != nil {
+		return err
+	}
+
+	// 发起时的所有者和接收方身份可能已经变化
+	fromRole, err := GetTeamUserRole(transfer.TeamID, transfer.FromUserID)
+	if err != nil {
+		return err
+	}
+	if fromRole != models.TeamRoleOwner {
+		return errors.New("转让发起人已不是团队所有者")
+	}
+	isMember, err := IsTeamMember(transfer.TeamID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return errors.New("您已不是团队成员")
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// 原所有者降为管理员
+		if err := tx.Model(&models.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", transfer.TeamID, transfer.FromUserID).
+			Update("role", models.TeamRoleAdmin).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", transfer.TeamID, userID).
+			Update("role", models.TeamRoleOwner).Error; err != nil {
+			return err
+		}
+		return tx.Model(&transfer).Updates(map[string]interface{}{
+			"status":     models.OwnershipTransferAccepted,
+			"updated_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return CreateMessage(&userID, transfer.FromUserID, models.MessageTypeTeamNotice,
+		"团队转让完成 - "+team.Name, fmt.Sprintf("团队 %s 已成功转让，您现在是该团队的管理员", team.Name))
+}
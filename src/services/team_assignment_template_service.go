@@ -0,0 +1,420 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 定时发布检查间隔
+const assignmentPublishInterval = time.Minute
+
+// applyPublishSchedule 根据隐藏标记和定时发布时间设置作业的发布状态
+func applyPublishSchedule(assignment *models.TeamAssignment, hidden bool, publishAt *time.Time) {
+	assignment.IsPublished = !hidden
+	assignment.PublishAt = nil
+	if publishAt != nil && publishAt.After(time.Now()) {
+		assignment.IsPublished = false
+		assignment.PublishAt = publishAt
+	}
+}
+
+// isAssignmentVisible 判断作业对指定角色是否可见，未发布的作业仅拥有作业管理权限的角色可见
+func isAssignmentVisible(assignment *models.TeamAssignment, role string) (bool, error) {
+	if assignment.IsPublished {
+		return true, nil
+	}
+	return TeamRoleHasPermission(assignment.TeamID, role, models.TeamPermCreateAssignment)
+}
+
+// checkAssignmentVisible 检查作业是否属于团队且对指定角色可见
+func checkAssignmentVisible(assignmentID uint64, teamID uint64, role string) (*models.TeamAssignment, error) {
+	var assignment models.TeamAssignment
+	if err := config.DB.Where("id = ? AND team_id = ?", assignmentID, teamID).First(&assignment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("作业不存在")
+		}
+		return nil, err
+	}
+	if visible, err := isAssignmentVisible(&assignment, role); err != nil {
+		return nil, err
+	} else if !visible {
+		return nil, errors.New("作业不存在")
+	}
+	return &assignment, nil
+}
+
+// validateTeamProblems 检查团队私有题目是否属于目标团队
+func validateTeamProblems(problems []models.TeamAssignmentProblem, teamID uint64) error {
+	for _, problem := range problems {
+		if problem.ProblemType != "team" || problem.TeamProblemID == nil {
+			continue
+		}
+		var count int64
+		if err := config.DB.Model(&models.TeamProblem{}).
+			Where("id = ? AND team_id = ?", *problem.TeamProblemID, teamID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("作业包含其他团队的私有题目，无法复制到该团队")
+		}
+	}
+	return nil
+}
+
+// insertAssignment 在事务中创建作业；需要立即发布的作业先以未发布状态写入，再通过 publishAssignmentTx 发布并通知团队成员
+func insertAssignment(tx *gorm.DB, assignment *models.TeamAssignment, operatorID *uint64) error {
+	publishNow := assignment.IsPublished
+	assignment.IsPublished = false
+	if err := tx.Create(assignment).Error; err != nil {
+		return err
+	}
+	if !publishNow {
+		return nil
+	}
+	if err := publishAssignmentTx(tx, assignment, operatorID); err != nil {
+		return err
+	}
+	assignment.IsPublished = true
+	return nil
+}
+
+// createAssignmentWithProblems 在事务中创建作业及其题目
+func createAssignmentWithProblems(assignment *models.TeamAssignment, problems []models.TeamAssignmentProblem) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := insertAssignment(tx, assignment, &assignment.CreatedBy); err != nil {
+			return err
+		}
+		for _, problem := range problems {
+			problem.AssignmentID = assignment.ID
+			if err := tx.Create(&problem).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CloneAssignment 复制作业（可复制到其他团队），所有时间按新的开始时间整体平移
+func CloneAssignment(assignmentID uint64, req *models.CloneAssignmentRequest, userID uint64) (uint64, error) {
+	var source models.TeamAssignment
+	if err := config.DB.First(&source, assignmentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.New("作业不存在")
+		}
+		return 0, err
+	}
+
+	targetTeamID := req.TargetTeamID
+	if targetTeamID == 0 {
+		targetTeamID = source.TeamID
+	}
+
+	// 需要在原团队和目标团队都拥有创建作业权限
+	for _, teamID := range []uint64{source.TeamID, targetTeamID} {
+		if _, err := AuthorizeTeam(teamID, userID, models.TeamPermCreateAssignment); err != nil {
+			return 0, err
+		}
+	}
+
+	var problems []models.TeamAssignmentProblem
+	if err := config.DB.Where("assignment_id = ?", assignmentID).Order("order_index").Find(&problems).Error; err != nil {
+		return 0, err
+	}
+	if err := validateTeamProblems(problems, targetTeamID); err != nil {
+		return 0, err
+	}
+
+	offset := req.StartTime.Sub(source.StartTime)
+	assignment := &models.TeamAssignment{
+		TeamID:             targetTeamID,
+		Title:              source.Title,
+		Description:        source.Description,
+		StartTime:          req.StartTime,
+		EndTime:            source.EndTime.Add(offset),
+		CreatedBy:          userID,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+		AllowLate:          source.AllowLate,
+		LatePenaltyPercent: source.LatePenaltyPercent,
+		LatePenaltyUnit:    source.LatePenaltyUnit,
+	}
+	if req.Title != "" {
+		assignment.Title = req.Title
+	}
+	if source.LateCutoff != nil {
+		lateCutoff := source.LateCutoff.Add(offset)
+		assignment.LateCutoff = &lateCutoff
+	}
+	applyPublishSchedule(assignment, req.Hidden, req.PublishAt)
+
+	if err := createAssignmentWithProblems(assignment, problems); err != nil {
+		return 0, err
+	}
+	return assignment.ID, nil
+}
+
+// SaveAssignmentTemplate 将作业保存为模板
+func SaveAssignmentTemplate(assignmentID uint64, req *models.SaveAssignmentTemplateRequest, userID uint64) (uint64, error) {
+	var assignment models.TeamAssignment
+	if err := config.DB.First(&assignment, assignmentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.New("作业不存在")
+		}
+		return 0, err
+	}
+
+	// 检查用户权限
+	if _, err := AuthorizeTeam(assignment.TeamID, userID, models.TeamPermCreateAssignment); err != nil {
+		return 0, err
+	}
+
+	var problems []models.TeamAssignmentProblem
+	if err := config.DB.Where("assignment_id = ?", assignmentID).Order("order_index").Find(&problems).Error; err != nil {
+		return 0, err
+	}
+
+	template := &models.TeamAssignmentTemplate{
+		TeamID:             assignment.TeamID,
+		Name:               req.Name,
+		Title:              assignment.Title,
+		Description:        assignment.Description,
+		DurationMinutes:    int(assignment.EndTime.Sub(assignment.StartTime).Minutes()),
+		AllowLate:          assignment.AllowLate,
+		LatePenaltyPercent: assignment.LatePenaltyPercent,
+		LatePenaltyUnit:    assignment.LatePenaltyUnit,
+		CreatedBy:          userID,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	if assignment.LateCutoff != nil {
+		minutes := int(assignment.LateCutoff.Sub(assignment.EndTime).Minutes())
+		template.LateCutoffMinutes = &minutes
+	}
+	for _, problem := range problems {
+		template.Problems = append(template.Problems, models.TeamAssignmentTemplateProblem{
+			ProblemID:     problem.ProblemID,
+			ProblemType:   problem.ProblemType,
+			TeamProblemID: problem.TeamProblemID,
+			OrderIndex:    problem.OrderIndex,
+			Score:         problem.Score,
+		})
+	}
+
+	// 模板与题目关联一起创建
+	if err := config.DB.Create(template).Error; err != nil {
+		return 0, err
+	}
+	return template.ID, nil
+}
+
+// GetAssignmentTemplates 获取团队的作业模板列表
+func GetAssignmentTemplates(teamID uint64, userID uint64) ([]models.TeamAssignmentTemplate, error) {
+	if _, err := AuthorizeTeam(teamID, userID, models.TeamPermCreateAssignment); err != nil {
+		return nil, err
+	}
+
+	var templates []models.TeamAssignmentTemplate
+	if err := config.DB.Preload("Problems", func(db *gorm.DB) *gorm.DB {
+		return db.Order("order_index")
+	}).Where("team_id = ?", teamID).
+		Order("created_at DESC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// DeleteAssignmentTemplate 删除作业模板
+func DeleteAssignmentTemplate(templateID uint64, userID uint64) error {
+	var template models.TeamAssignmentTemplate
+	if err := config.DB.First(&template, templateID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("模板不存在")
+		}
+		return err
+	}
+
+	if _, err := AuthorizeTeam(template.TeamID, userID, models.TeamPermCreateAssignment); err != nil {
+		return err
+	}
+
+	return config.DB.Delete(&template).Error
+}
+
+// CreateAssignmentFromTemplate 从模板创建作业（可创建到其他团队）
+func CreateAssignmentFromTemplate(req *models.CreateAssignmentFromTemplateRequest, userID uint64) (uint64, error) {
+	var template models.TeamAssignmentTemplate
+	if err := config.DB.Preload("Problems").First(&template, req.TemplateID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.New("模板不存在")
+		}
+		return 0, err
+	}
+
+	// 需要在模板所属团队和目标团队都拥有创建作业权限
+	for _, teamID := range []uint64{template.TeamID, req.TeamID} {
+		if _, err := AuthorizeTeam(teamID, userID, models.TeamPermCreateAssignment); err != nil {
+			return 0, err
+		}
+	}
+
+	problems := make([]models.TeamAssignmentProblem, 0, len(template.Problems))
+	for _, problem := range template.Problems {
+		problems = append(problems, models.TeamAssignmentProblem{
+			ProblemID:     problem.ProblemID,
+			ProblemType:   problem.ProblemType,
+			TeamProblemID: problem.TeamProblemID,
+			OrderIndex:    problem.OrderIndex,
+			Score:         problem.Score,
+		})
+	}
+	if err := validateTeamProblems(problems, req.TeamID); err != nil {
+		return 0, err
+	}
+
+	assignment := &models.TeamAssignment{
+		TeamID:             req.TeamID,
+		Title:              template.Title,
+		Description:        template.Description,
+		StartTime:          req.StartTime,
+		EndTime:            req.StartTime.Add(time.Duration(template.DurationMinutes) * time.Minute),
+		CreatedBy:          userID,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+		AllowLate:          template.AllowLate,
+		LatePenaltyPercent: template.LatePenaltyPercent,
+		LatePenaltyUnit:    template.LatePenaltyUnit,
+	}
+	if req.Title != "" {
+		assignment.Title = req.Title
+	}
+	if template.LateCutoffMinutes != nil {
+		lateCutoff := assignment.EndTime.Add(time.Duration(*template.LateCutoffMinutes) * time.Minute)
+		assignment.LateCutoff = &lateCutoff
+	}
+	applyPublishSchedule(assignment, req.Hidden, req.PublishAt)
+
+	if err := createAssignmentWithProblems(assignment, problems); err != nil {
+		return 0, err
+	}
+	return assignment.ID, nil
+}
+
+// PublishAssignment 立即发布作业
+func PublishAssignment(assignmentID uint64, userID uint64) error {
+	var assignment models.TeamAssignment
+	if err := config.DB.First(&assignment, assignmentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("作业不存在")
+		}
+		return err
+	}
+
+	if _, err := AuthorizeTeam(assignment.TeamID, userID, models.TeamPermCreateAssignment); err != nil {
+		return err
+	}
+	if assignment.IsPublished {
+		return errors.New("作业已发布")
+	}
+
+	return publishAssignment(&assignment, &userID)
+}
+
+// publishAssignment 将作业标记为已发布并通知团队成员，重复发布不会重复通知；
+// 发布标记与通知在同一事务中写入，通知失败时作业保持未发布，定时任务会再次尝试
+func publishAssignment(assignment *models.TeamAssignment, operatorID *uint64) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		return publishAssignmentTx(tx, assignment, operatorID)
+	})
+}
+
+// publishAssignmentTx 在调用方的事务中发布作业，所有发布作业的路径都应通过该方法以保证成员收到通知
+func publishAssignmentTx(tx *gorm.DB, assignment *models.TeamAssignment, operatorID *uint64) error {
+	var memberIDs []uint64
+	if err := tx.Model(&models.TeamMember{}).
+		Where("team_id = ?", assignment.TeamID).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	title := "新作业发布 - " + assignment.Title
+	content := fmt.Sprintf("作业 %s 已发布，开始时间：%s，截止时间：%s",
+		assignment.Title,
+		assignment.StartTime.Format("2006-01-02 15:04"),
+		assignment.EndTime.Format("2006-01-02 15:04"))
+	messages := make([]models.Message, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if operatorID != nil && memberID == *operatorID {
+			continue
+		}
+		messages = append(messages, models.Message{
+			SenderID:   operatorID,
+			ReceiverID: memberID,
+			Type:       models.MessageTypeTeamNotice,
+			Title:      title,
+			Content:    content,
+			CreatedAt:  now,
+		})
+	}
+
+	result := tx.Model(&models.TeamAssignment{}).
+		Where("id = ? AND is_published = ?", assignment.ID, false).
+		Updates(map[string]interface{}{
+			"is_published": true,
+			"publish_at":   nil,
+			"updated_at":   now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if len(messages) > 0 {
+		if err := tx.Create(&messages).Error; err != nil {
+			return err
+		}
+	}
+	return recordTeamEvent(tx, assignment.TeamID, operatorID, models.TeamEventAssignmentPublished, assignment.ID, "发布了作业 "+assignment.Title)
+}
+
+// PublishScheduledAssignments 发布所有已到定时发布时间的作业
+func PublishScheduledAssignments() error {
+	var assignments []models.TeamAssignment
+	if err := config.DB.Where("is_published = ? AND publish_at IS NOT NULL AND publish_at <= ?", false, time.Now()).
+		Where("team_id NOT IN (?)", config.DB.Model(&models.Team{}).Select("id").Where("archived_at IS NOT NULL")).
+		Find(&assignments).Error; err != nil {
+		return err
+	}
+
+	for i := range assignments {
+		if err := publishAssignment(&assignments[i], nil); err != nil {
+			logrus.Errorf("定时发布作业 %d 失败: %v", assignments[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// StartAssignmentPublishScheduler 启动作业定时发布任务
+func StartAssignmentPublishScheduler() {
+	go func() {
+		ticker := time.NewTicker(assignmentPublishInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := PublishScheduledAssignments(); err != nil {
+				logrus.Errorf("检查定时发布作业失败: %v", err)
+			}
+		}
+	}()
+}
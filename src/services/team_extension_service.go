@@ -0,0 +1,171 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetAssignmentDeadline 获取成员的作业截止时间，存在延期时以延期后的时间为准
+func GetAssignmentDeadline(assignment *models.TeamAssignment, userID uint64) (time.Time, error) {
+	var extension models.TeamAssignmentExtension
+	err := config.DB.Where("assignment_id = ? AND user_id = ?", assignment.ID, userID).First(&extension).Error
+	if err == gorm.ErrRecordNotFound {
+		return assignment.EndTime, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if extension.EndTime.After(assignment.EndTime) {
+		return extension.EndTime, nil
+	}
+	return assignment.EndTime, nil
+}
+
+// getAssignmentExtensionMap 获取作业所有成员的延期截止时间
+func getAssignmentExtensionMap(assignmentID uint64) (map[uint64]time.Time, error) {
+	var extensions []models.TeamAssignmentExtension
+	if err := config.DB.Where("assignment_id = ?", assignmentID).Find(&extensions).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]time.Time, len(extensions))
+	for _, extension := range extensions {
+		result[extension.UserID] = extension.EndTime
+	}
+	return result, nil
+}
+
+// CalculateLatePenalty 计算迟交扣除的分数百分比（0-100）
+// 未超过截止时间不扣分；不允许迟交或超过迟交截止时间的提交不得分
+func CalculateLatePenalty(assignment *models.TeamAssignment, deadline time.Time, submittedAt time.Time) int {
+	if !submittedAt.After(deadline) {
+		return 0
+	}
+	if !assignment.AllowLate {
+		return 100
+	}
+	if assignment.LateCutoff != nil && submittedAt.After(*assignment.LateCutoff) {
+		return 100
+	}
+
+	unit := 24 * time.Hour
+	if assignment.LatePenaltyUnit == models.LatePenaltyUnitHour {
+		unit = time.Hour
+	}
+
+	// 不足一个计时单位按一个单位计算
+	units := int(math.Ceil(float64(submittedAt.Sub(deadline)) / float64(unit)))
+	penalty := units * assignment.LatePenaltyPercent
+	if penalty > 100 {
+		return 100
+	}
+	return penalty
+}
+
+// GrantAssignmentExtension 为成员延期作业截止时间
+func GrantAssignmentExtension(assignmentID uint64, req *models.GrantAssignmentExtensionRequest, operatorID uint64) error {
+	var assignment models.TeamAssignment
+	if err := config.DB.First(&assignment, assignmentID).Error; err != nil {
+		return err
+	}
+
+	// 检查操作者权限
+	if _, err := AuthorizeTeam(assignment.TeamID, operatorID, models.TeamPermCreateAssignment); err != nil {
+		return err
+	}
+
+	// 检查目标用户是否是团队成员
+	isMember, err := IsTeamMember(assignment.TeamID, req.UserID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return errors.New("用户不是团队成员")
+	}
+
+	if !req.EndTime.After(assignment.EndTime) {
+		return errors.New("延期时间必须晚于作业结束时间")
+	}
+
+	err = config.DB.Exec(`
+		INSERT INTO team_assignment_extensions (assignment_id, user_id, end_time, reason, granted_by)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		end_time = VALUES(end_time),
+		reason = VALUES(reason),
+		granted_by = VALUES(granted_by),
+		updated_at = CURRENT_TIMESTAMP
+	`, assignmentID, req.UserID, req.EndTime, req.Reason, operatorID).Error
+	if err != nil {
+		return err
+	}
+
+	// 通知成员
+	content := fmt.Sprintf("您在作业 %s 的截止时间已延期至 %s", assignment.Title, req.EndTime.Format("2006-01-02 15:04"))
+	if req.Reason != "" {
+		content += "\n延期原因：" + req.Reason
+	}
+	return CreateMessage(&operatorID, req.UserID, models.MessageTypeTeamNotice, "作业延期通知 - "+assignment.Title, content)
+}
+
+// RevokeAssignmentExtension 撤销成员的作业延期
+func RevokeAssignmentExtension(assignmentID uint64, targetUserID uint64, operatorID uint64) error {
+	var assignment models.TeamAssignment
+	if err := config.DB.First(&assignment, assignmentID).Error; err != nil {
+		return err
+	}
+
+	// 检查操作者权限
+	if _, err := AuthorizeTeam(assignment.TeamID, operatorID, models.TeamPermCreateAssignment); err != nil {
+		return err
+	}
+
+	result := config.DB.Where("assignment_id = ? AND user_id = ?", assignmentID, targetUserID).
+		Delete(&models.TeamAssignmentExtension{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该成员没有延期记录")
+	}
+
+	return nil
+}
+
+// GetAssignmentExtensions 获取作业的延期列表
+func GetAssignmentExtensions(assignmentID uint64, userID uint64) ([]models.TeamAssignmentExtension, error) {
+	var assignment models.TeamAssignment
+	if err := config.DB.First(&assignment, assignmentID).Error; err != nil {
+		return nil, err
+	}
+
+	role, err := AuthorizeTeam(assignment.TeamID, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("assignment_id = ?", assignmentID)
+
+	// 没有作业管理权限的成员只能查看自己的延期
+	canManage, err := TeamRoleHasPermission(assignment.TeamID, role, models.TeamPermCreateAssignment)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var extensions []models.TeamAssignmentExtension
+	if err := query.Order("end_time DESC").Find(&extensions).Error; err != nil {
+		return nil, err
+	}
+
+	return extensions, nil
+}
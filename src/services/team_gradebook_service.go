@@ -0,0 +1,230 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 成绩表单元格状态
+const (
+	GradebookStatusAccepted  = "accepted"  // 已通过
+	GradebookStatusAttempted = "attempted" // 尝试过
+	GradebookStatusUntouched = "untouched" // 未提交
+)
+
+// gradebookSubmission 计算成绩表所需的提交记录字段
+type gradebookSubmission struct {
+	ID          uint64
+	UserID      uint64
+	ProblemID   uint64
+	Status      string
+	ManualScore *int // 人工评分，为空表示沿用自动评分
+	CreatedAt   time.Time
+}
+
+// GetAssignmentGradebook 获取作业成绩表
+func GetAssignmentGradebook(req *models.GetAssignmentGradebookRequest, userID uint64) (*models.AssignmentGradebookResponse, error) {
+	// 检查用户权限
+	if _, err := AuthorizeTeam(req.TeamID, userID, models.TeamPermViewSubmissions); err != nil {
+		return nil, err
+	}
+
+	var assignment models.TeamAssignment
+	if err := config.DB.Where("id = ? AND team_id = ?", req.AssignmentID, req.TeamID).First(&assignment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("作业不存在")
+		}
+		return nil, err
+	}
+
+	// 获取作业题目
+	var problems []models.GradebookProblem
+	if err := config.DB.Table("team_assignment_problems tap").
+		Select(`
+			tap.problem_id,
+			tap.problem_type,
+			CASE
+				WHEN tap.problem_type = 'global' THEN p.title
+				ELSE tp.title
+			END as title,
+			tap.score,
+			tap.order_index
+		`).
+		Joins("LEFT JOIN problems p ON tap.problem_type = 'global' AND p.id = tap.problem_id").
+		Joins("LEFT JOIN team_problems tp ON tap.problem_type = 'team' AND tp.id = tap.team_problem_id").
+		Where("tap.assignment_id = ?", assignment.ID).
+		Order("tap.order_index").
+		Scan(&problems).Error; err != nil {
+		return nil, err
+	}
+
+	// 获取参与作业的团队成员：可以提交作业且不负责批阅的角色
+	rolePermissions, err := getTeamRolePermissionMap(req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	var studentRoles []string
+	for role, permissions := range rolePermissions {
+		if role != models.TeamRoleOwner && containsString(permissions, models.TeamPermSubmitAssignment) && !containsString(permissions, models.TeamPermGrade) {
+			studentRoles = append(studentRoles, role)
+		}
+	}
+
+	var members []struct {
+		UserID   uint64
+		Username string
+		Nickname string
+	}
+	if len(studentRoles) > 0 {
+		if err := config.DB.Table("team_members tm").
+			Select("tm.user_id, u.username, COALESCE(tn.nickname, '') as nickname").
+			Joins("JOIN users u ON u.id = tm.user_id").
+			Joins("LEFT JOIN team_nicknames tn ON tn.team_id = tm.team_id AND tn.user_id = tm.user_id").
+			Where("tm.team_id = ? AND tm.role IN ?", req.TeamID, studentRoles).
+			Order("u.username").
+			Scan(&members).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// 获取作业的全部提交记录，按提交时间升序
+	var submissions []gradebookSubmission
+	if err := config.DB.Table("submissions s").
+		Select("s.id, s.user_id, s.problem_id, s.status, sr.manual_score, s.created_at").
+		Joins("LEFT JOIN submission_reviews sr ON sr.submission_id = s.id").
+		Where("s.assignment_id = ?", assignment.ID).
+		Order("s.created_at ASC, s.id ASC").
+		Scan(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	// 按用户和题目分组
+	type cellKey struct {
+		UserID    uint64
+		ProblemID uint64
+	}
+	grouped := make(map[cellKey][]gradebookSubmission)
+	for _, s := range submissions {
+		key := cellKey{UserID: s.UserID, ProblemID: s.ProblemID}
+		grouped[key] = append(grouped[key], s)
+	}
+
+	// 获取成员延期
+	extensions, err := getAssignmentExtensionMap(assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AssignmentGradebookResponse{
+		Assignment: assignment,
+		Problems:   problems,
+		Rows:       make([]models.GradebookRow, 0, len(members)),
+	}
+	for _, problem := range problems {
+		response.FullScore += problem.Score
+	}
+
+	for _, member := range members {
+		row := models.GradebookRow{
+			UserID:   member.UserID,
+			Username: member.Username,
+			Nickname: member.Nickname,
+			Deadline: assignment.EndTime,
+			Cells:    make([]models.GradebookCell, len(problems)),
+		}
+		if extendedUntil, ok := extensions[member.UserID]; ok && extendedUntil.After(assignment.EndTime) {
+			row.Deadline = extendedUntil
+			row.Extended = true
+		}
+
+		for i, problem := range problems {
+			cell := buildGradebookCell(&assignment, row.Deadline, problem, grouped[cellKey{UserID: member.UserID, ProblemID: problem.ProblemID}])
+			row.Cells[i] = cell
+			row.TotalScore += cell.Score
+			if cell.Status == GradebookStatusAccepted {
+				row.SolvedCount++
+			}
+		}
+
+		response.Rows = append(response.Rows, row)
+	}
+
+	return response, nil
+}
+
+// buildGradebookCell 根据某成员某题的提交记录（按时间升序）计算单元格，迟交的提交按迟交策略扣分，
+// 存在人工评分时以最后一次人工评分为准
+func buildGradebookCell(assignment *models.TeamAssignment, deadline time.Time, problem models.GradebookProblem, submissions []gradebookSubmission) models.GradebookCell {
+	cell := models.GradebookCell{
+		ProblemID: problem.ProblemID,
+		Status:    GradebookStatusUntouched,
+		Attempts:  len(submissions),
+	}
+	if len(submissions) == 0 {
+		return cell
+	}
+
+	cell.Status = GradebookStatusAttempted
+	cell.LastStatus = submissions[len(submissions)-1].Status
+
+	for _, s := range submissions {
+		if !isAcceptedStatus(s.Status) {
+			continue
+		}
+		if cell.FirstAcceptedAt == nil {
+			acceptedAt := s.CreatedAt
+			cell.FirstAcceptedAt = &acceptedAt
+			cell.Status = GradebookStatusAccepted
+		}
+
+		penalty := CalculateLatePenalty(assignment, deadline, s.CreatedAt)
+		score := problem.Score * (100 - penalty) / 100
+		if score > cell.Score || (score == cell.Score && cell.IsLate && penalty < cell.PenaltyPercent) {
+			cell.Score = score
+			cell.IsLate = penalty > 0
+			cell.PenaltyPercent = penalty
+		}
+	}
+
+	for i := len(submissions) - 1; i >= 0; i-- {
+		if submissions[i].ManualScore != nil {
+			cell.Score = *submissions[i].ManualScore
+			cell.ManuallyGraded = true
+			break
+		}
+	}
+
+	return cell
+}
+
+// isAcceptedStatus 判断判题状态是否为通过（兼容历史数据中的大小写差异）
+func isAcceptedStatus(status string) bool {
+	return strings.EqualFold(status, models.StatusAccepted)
+}
+
+// BuildGradebookTable 将成绩表转换为二维表格，用于导出
+func BuildGradebookTable(gradebook *models.AssignmentGradebookResponse) [][]string {
+	header := []string{"用户ID", "用户名", "团队内名称"}
+	for _, problem := range gradebook.Problems {
+		header = append(header, fmt.Sprintf("%s (%d)", problem.Title, problem.Score))
+	}
+	header = append(header, "通过题数", "总分")
+
+	table := [][]string{header}
+	for _, row := range gradebook.Rows {
+		line := []string{fmt.Sprint(row.UserID), row.Username, row.Nickname}
+		for _, cell := range row.Cells {
+			line = append(line, fmt.Sprint(cell.Score))
+		}
+		line = append(line, fmt.Sprint(row.SolvedCount), fmt.Sprint(row.TotalScore))
+		table = append(table, line)
+	}
+
+	return table
+}
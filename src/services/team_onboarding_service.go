@@ -0,0 +1,283 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 单次批量导入的最大行数
+const maxOnboardRows = 1000
+
+// ParseOnboardingCSV 解析批量导入 CSV，列顺序：用户名、真实姓名、邮箱、学号、团队内名称（可选），首行可以是表头
+func ParseOnboardingCSV(r io.Reader) ([]models.OnboardMemberRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析 CSV 失败: %v", err)
+	}
+
+	rows := make([]models.OnboardMemberRow, 0, len(records))
+	for i, record := range records {
+		if i == 0 && len(record) > 0 {
+			// 去掉 Excel 导出时附带的 BOM，并跳过表头
+			record[0] = strings.TrimPrefix(record[0], "\xEF\xBB\xBF")
+			header := strings.ToLower(strings.TrimSpace(record[0]))
+			if header == "username" || header == "用户名" {
+				continue
+			}
+		}
+
+		fields := make([]string, 5)
+		for j := 0; j < len(record) && j < len(fields); j++ {
+			fields[j] = strings.TrimSpace(record[j])
+		}
+		if strings.Join(fields, "") == "" {
+			continue
+		}
+
+		rows = append(rows, models.OnboardMemberRow{
+			Line:      i + 1,
+			Username:  fields[0],
+			RealName:  fields[1],
+			Email:     fields[2],
+			StudentID: fields[3],
+			Nickname:  fields[4],
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("CSV 中没有可导入的数据")
+	}
+	if len(rows) > maxOnboardRows {
+		return nil, fmt.Errorf("单次最多导入 %d 人", maxOnboardRows)
+	}
+	return rows, nil
+}
+
+// BulkOnboardTeamMembers 批量创建或查找用户并加入团队；新建账号使用随机密码，首次登录需修改密码。
+// 由于会创建账号，只有网站管理员或团队所属组织的管理员可以操作
+func BulkOnboardTeamMembers(teamID uint64, rows []models.OnboardMemberRow, role string, operatorID uint64) (*models.BulkOnboardResponse, error) {
+	operatorRole, err := AuthorizeTeam(teamID, operatorID, models.TeamPermManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	if operatorRole != models.TeamRoleOrgAdmin {
+		isAdmin, err := HasSitePermission(operatorID, models.SitePermUserCreate)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, errors.New("只有网站管理员或团队所属组织的管理员可以批量导入成员")
+		}
+	}
+
+	if role == "" {
+		role = models.TeamRoleMember
+	}
+	if role == models.TeamRoleOwner {
+		return nil, errors.New("无效的角色")
+	}
+	exists, err := teamRoleExists(teamID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("无效的角色")
+	}
+
+	var team models.Team
+	if err := config.DB.First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+
+	response := &models.BulkOnboardResponse{
+		Results: make([]models.OnboardMemberResult, 0, len(rows)),
+	}
+	for _, row := range rows {
+		result := onboardTeamMember(teamID, row, role)
+		switch result.Status {
+		case models.OnboardStatusCreated:
+			// 新账号创建时已标记 MustChangePassword，登录时会同步强制改密标记
+			response.Created++
+		case models.OnboardStatusJoined:
+			response.Joined++
+			// 成员已经加入团队，通知发送失败只记录在该行结果中，不能中断导入，否则已创建账号的初始密码会丢失
+			content := fmt.Sprintf("您已被加入团队 %s", team.Name)
+			if err := CreateMessage(&operatorID, result.UserID, models.MessageTypeTeamNotice, "加入团队通知 - "+team.Name, content); err != nil {
+				logrus.Errorf("批量导入时通知用户 %d 加入团队 %d 失败: %v", result.UserID, teamID, err)
+				result.Error = "已加入团队，但通知发送失败"
+			}
+		case models.OnboardStatusFailed:
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+// onboardTeamMember 导入单个成员，每个成员使用独立事务，失败不影响其他成员
+func onboardTeamMember(teamID uint64, row models.OnboardMemberRow, role string) models.OnboardMemberResult {
+	result := models.OnboardMemberResult{OnboardMemberRow: row}
+	fail := func(msg string) models.OnboardMemberResult {
+		result.Status = models.OnboardStatusFailed
+		result.Error = msg
+		return result
+	}
+
+	if row.Username == "" {
+		return fail("用户名不能为空")
+	}
+	if len(row.Username) > 50 {
+		return fail("用户名过长")
+	}
+	if row.Email != "" && (len(row.Email) > 100 || !strings.Contains(row.Email, "@")) {
+		return fail("邮箱格式错误")
+	}
+	if len(row.RealName) > 50 || len(row.StudentID) > 50 {
+		return fail("真实姓名或学号过长")
+	}
+	nickname := row.Nickname
+	if nickname == "" {
+		nickname = row.RealName
+	}
+	if len(nickname) > 50 {
+		return fail("团队内名称过长")
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Where("username = ?", row.Username).First(&user).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		if err == gorm.ErrRecordNotFound {
+			if row.Email != "" {
+				var count int64
+				if err := tx.Model(&models.User{}).Where("email = ?", row.Email).Count(&count).Error; err != nil {
+					return err
+				}
+				if count > 0 {
+					return errors.New("邮箱已被注册")
+				}
+			}
+
+			password := generateRandomPassword(10)
+			hashedPassword, err := HashPassword(password)
+			if err != nil {
+				return fmt.Errorf("密码加密失败: %v", err)
+			}
+
+			user = models.User{
+				Username:           row.Username,
+				Password:           hashedPassword,
+				Email:              row.Email,
+				MustChangePassword: true,
+			}
+			// 未提供邮箱时不写入该列，避免空字符串触发唯一约束
+			fields := []string{"Username", "Password", "MustChangePassword"}
+			if row.Email != "" {
+				fields = append(fields, "Email")
+			}
+			if err := tx.Select(fields).Create(&user).Error; err != nil {
+				return fmt.Errorf("创建用户失败: %v", err)
+			}
+
+			now := time.Now()
+			profile := models.Profile{
+				UserID:    int(user.ID),
+				RealName:  row.RealName,
+				StudentID: row.StudentID,
+				CreateAt:  now,
+				UpdateAt:  now,
+			}
+			if err := tx.Select("user_id", "real_name", "student_id", "create_at", "update_at").Create(&profile).Error; err != nil {
+				return fmt.Errorf("创建用户资料失败: %v", err)
+			}
+
+			result.Password = password
+			result.Status = models.OnboardStatusCreated
+		} else {
+			result.Status = models.OnboardStatusJoined
+		}
+		result.UserID = user.ID
+
+		var count int64
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, user.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			result.Status = models.OnboardStatusAlreadyMember
+			return nil
+		}
+
+		if err := tx.Create(&models.TeamMember{
+			TeamID:   teamID,
+			UserID:   user.ID,
+			Role:     role,
+			JoinedAt: time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		if err := recordTeamEvent(tx, teamID, &user.ID, models.TeamEventMemberJoined, user.ID, "通过批量导入加入了团队"); err != nil {
+			return err
+		}
+
+		if nickname == "" {
+			return nil
+		}
+		return tx.Exec(`
+			INSERT INTO team_nicknames (team_id, user_id, nickname)
+			VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+			nickname = VALUES(nickname),
+			updated_at = CURRENT_TIMESTAMP
+		`, teamID, user.ID, nickname).Error
+	})
+	if err != nil {
+		result.Password = ""
+		return fail(err.Error())
+	}
+	return result
+}
+
+// BuildOnboardCredentialTable 生成导入结果的凭据表，用于导出 CSV 分发给成员
+func BuildOnboardCredentialTable(response *models.BulkOnboardResponse) [][]string {
+	statusNames := map[string]string{
+		models.OnboardStatusCreated:       "新建账号",
+		models.OnboardStatusJoined:        "已有账号",
+		models.OnboardStatusAlreadyMember: "已是成员",
+		models.OnboardStatusFailed:        "导入失败",
+	}
+
+	table := [][]string{{"用户名", "真实姓名", "邮箱", "学号", "初始密码", "状态", "备注"}}
+	for _, result := range response.Results {
+		note := result.Error
+		if result.Status == models.OnboardStatusCreated {
+			note = "首次登录需修改密码"
+		}
+		table = append(table, []string{
+			result.Username,
+			result.RealName,
+			result.Email,
+			result.StudentID,
+			result.Password,
+			statusNames[result.Status],
+			note,
+		})
+	}
+	return table
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// problemListUserProgress 用户在题单各题目上的提交情况
+type problemListUserProgress struct {
+	solved       map[uint64]bool
+	attempted    map[uint64]bool
+	lastSolvedAt *time.Time
+}
+
+// statusOf 获取题目的完成状态
+func (p *problemListUserProgress) statusOf(problemID uint64) string {
+	if p == nil {
+		return models.ProblemProgressUntouched
+	}
+	if p.solved[problemID] {
+		return models.ProblemProgressSolved
+	}
+	if p.attempted[problemID] {
+		return models.ProblemProgressAttempted
+	}
+	return models.ProblemProgressUntouched
+}
+
+// getProblemListItems 获取题单题目及标题，按题单顺序排列
+func getProblemListItems(listID uint64) ([]models.ProblemListItemProgress, error) {
+	items := make([]models.ProblemListItemProgress, 0)
+	if err := config.DB.Table("team_problem_list_items i").
+		Select("i.*, p.title").
+		Joins("JOIN problems p ON p.id = i.problem_id").
+		Where("i.list_id = ?", listID).
+		Order("i.order_index").
+		Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// getProblemListProgress 根据提交记录统计用户在题单各题目上的完成情况
+func getProblemListProgress(items []models.ProblemListItemProgress, userIDs []uint64) (map[uint64]*problemListUserProgress, error) {
+	result := make(map[uint64]*problemListUserProgress, len(userIDs))
+	if len(items) == 0 || len(userIDs) == 0 {
+		return result, nil
+	}
+
+	problemIDs := make([]uint64, len(items))
+	for i, item := range items {
+		problemIDs[i] = item.ProblemID
+	}
+
+	var rows []struct {
+		UserID       uint64
+		ProblemID    uint64
+		Solved       bool
+		FirstSolveAt *time.Time
+	}
+	if err := config.DB.Table("submissions").
+		Select(`
+			user_id,
+			problem_id,
+			MAX(status = 'accepted') as solved,
+			MIN(CASE WHEN status = 'accepted' THEN created_at END) as first_solve_at
+		`).
+		Where("problem_id IN ? AND user_id IN ?", problemIDs, userIDs).
+		Group("user_id, problem_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		progress, ok := result[row.UserID]
+		if !ok {
+			progress = &problemListUserProgress{
+				solved:    make(map[uint64]bool),
+				attempted: make(map[uint64]bool),
+			}
+			result[row.UserID] = progress
+		}
+		progress.attempted[row.ProblemID] = true
+		if row.Solved {
+			progress.solved[row.ProblemID] = true
+			if row.FirstSolveAt != nil && (progress.lastSolvedAt == nil || row.FirstSolveAt.After(*progress.lastSolvedAt)) {
+				progress.lastSolvedAt = row.FirstSolveAt
+			}
+		}
+	}
+	return result, nil
+}
+
+// completionRate 计算完成百分比，保留一位小数
+func completionRate(solved int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(solved)*1000/float64(total)) / 10
+}
+
+// GetProblemListProgress 获取题单的成员进度和排行榜。
+// 团队成员都可以查看排行榜；拥有查看提交权限的成员可以看到所有人每道题的状态，其他成员只能看到自己的
+func GetProblemListProgress(listID uint64, userID uint64) (*models.ProblemListProgressResponse, error) {
+	var list models.TeamProblemList
+	if err := config.DB.First(&list, listID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("题单不存在")
+		}
+		return nil, err
+	}
+
+	if _, err := AuthorizeTeam(list.TeamID, userID, ""); err != nil {
+		return nil, err
+	}
+	canViewAll, err := HasTeamPermission(list.TeamID, userID, models.TeamPermViewSubmissions)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := getProblemListItems(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []struct {
+		UserID   uint64
+		Username string
+		Nickname string
+	}
+	if err := config.DB.Table("team_members tm").
+		Select("tm.user_id, u.username, COALESCE(tn.nickname, '') as nickname").
+		Joins("JOIN users u ON u.id = tm.user_id").
+		Joins("LEFT JOIN team_nicknames tn ON tn.team_id = tm.team_id AND tn.user_id = tm.user_id").
+		Where("tm.team_id = ?", list.TeamID).
+		Scan(&members).Error; err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint64, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+	progress, err := getProblemListProgress(items, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.ProblemListMemberProgress, len(members))
+	for i, member := range members {
+		entry := models.ProblemListMemberProgress{
+			UserID:   member.UserID,
+			Username: member.Username,
+			Nickname: member.Nickname,
+		}
+		userProgress := progress[member.UserID]
+		if userProgress != nil {
+			entry.SolvedCount = len(userProgress.solved)
+			entry.AttemptedCount = len(userProgress.attempted) - len(userProgress.solved)
+			entry.LastSolvedAt = userProgress.lastSolvedAt
+		}
+		entry.CompletionRate = completionRate(entry.SolvedCount, len(items))
+
+		if canViewAll || member.UserID == userID {
+			entry.Items = make(map[uint64]string, len(items))
+			for _, item := range items {
+				entry.Items[item.ProblemID] = userProgress.statusOf(item.ProblemID)
+			}
+		}
+		entries[i] = entry
+	}
+
+	// 按通过题数降序排列，通过题数相同时先完成的在前
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.SolvedCount != b.SolvedCount {
+			return a.SolvedCount > b.SolvedCount
+		}
+		if a.LastSolvedAt != nil && b.LastSolvedAt != nil {
+			return a.LastSolvedAt.Before(*b.LastSolvedAt)
+		}
+		return a.UserID < b.UserID
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].SolvedCount == entries[i-1].SolvedCount && entries[i].SolvedCount == 0 {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+
+	return &models.ProblemListProgressResponse{
+		ListID:     listID,
+		TotalCount: len(items),
+		Members:    entries,
+	}, nil
+}
@@ -3,7 +3,6 @@ package services
 import (
 	"OptiOJ/src/config"
 	"OptiOJ/src/models"
-	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,13 +11,9 @@ import (
 // CreateTeamProblem 创建团队私有题目
 func CreateTeamProblem(req *models.CreateTeamProblemRequest, userID uint64) (uint64, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(req.TeamID, userID, models.TeamPermManageProblems); err != nil {
 		return 0, err
 	}
-	if role != "owner" && role != "admin" {
-		return 0, errors.New("权限不足")
-	}
 
 	problem := &models.TeamProblem{
 		TeamID:            req.TeamID,
@@ -66,13 +61,15 @@ func UpdateTeamProblem(problemID uint64, req *models.UpdateTeamProblemRequest, u
 		return err
 	}
 
-	// 检查用户权限
-	role, err := GetTeamUserRole(problem.TeamID, userID)
-	if err != nil {
+	if err := checkTeamWritable(problem.TeamID); err != nil {
 		return err
 	}
-	if role != "owner" && role != "admin" && problem.CreatedBy != userID {
-		return errors.New("权限不足")
+
+	// 检查用户权限，题目创建者可以直接操作
+	if problem.CreatedBy != userID {
+		if _, err := AuthorizeTeam(problem.TeamID, userID, models.TeamPermManageProblems); err != nil {
+			return err
+		}
 	}
 
 	return config.DB.Transaction(func(tx *gorm.DB) error {
@@ -141,13 +138,15 @@ func DeleteTeamProblem(problemID uint64, userID uint64) error {
 		return err
 	}
 
-	// 检查用户权限
-	role, err := GetTeamUserRole(problem.TeamID, userID)
-	if err != nil {
+	if err := checkTeamWritable(problem.TeamID); err != nil {
 		return err
 	}
-	if role != "owner" && role != "admin" && problem.CreatedBy != userID {
-		return errors.New("权限不足")
+
+	// 检查用户权限，题目创建者可以直接操作
+	if problem.CreatedBy != userID {
+		if _, err := AuthorizeTeam(problem.TeamID, userID, models.TeamPermManageProblems); err != nil {
+			return err
+		}
 	}
 
 	return config.DB.Transaction(func(tx *gorm.DB) error {
@@ -156,6 +155,14 @@ func DeleteTeamProblem(problemID uint64, userID uint64) error {
 			return err
 		}
 
+		// 删除共享记录和推荐申请，已进入公共题库的题目不受影响
+		if err := tx.Where("problem_id = ?", problemID).Delete(&models.TeamProblemShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_problem_id = ?", problemID).Delete(&models.TeamProblemPromotion{}).Error; err != nil {
+			return err
+		}
+
 		// 删除题目
 		return tx.Delete(&problem).Error
 	})
@@ -169,12 +176,17 @@ func GetTeamProblemDetail(problemID uint64, userID uint64) (*models.TeamProblemD
 	}
 
 	// 检查用户权限
-	role, err := GetTeamUserRole(problem.TeamID, userID)
+	role, err := AuthorizeTeam(problem.TeamID, userID, "")
 	if err != nil {
-		return nil, err
-	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
+		// 题目共享给了用户所在团队时只读可见，不返回测试用例
+		shared, shareErr := isTeamProblemSharedWithUser(problemID, userID)
+		if shareErr != nil {
+			return nil, shareErr
+		}
+		if !shared {
+			return nil, err
+		}
+		return &models.TeamProblemDetail{TeamProblem: problem}, nil
 	}
 
 	detail := &models.TeamProblemDetail{
@@ -182,7 +194,11 @@ func GetTeamProblemDetail(problemID uint64, userID uint64) (*models.TeamProblemD
 	}
 
 	// 获取测试用例
-	if role == "owner" || role == "admin" || problem.CreatedBy == userID {
+	canManage, err := TeamRoleHasPermission(problem.TeamID, role, models.TeamPermManageProblems)
+	if err != nil {
+		return nil, err
+	}
+	if canManage || problem.CreatedBy == userID {
 		var testCases []models.TeamProblemTestCase
 		if err := config.DB.Where("problem_id = ?", problemID).Find(&testCases).Error; err != nil {
 			return nil, err
@@ -196,13 +212,9 @@ func GetTeamProblemDetail(problemID uint64, userID uint64) (*models.TeamProblemD
 // GetTeamProblemList 获取团队私有题目列表
 func GetTeamProblemList(req *models.TeamProblemListRequest, userID uint64) (*models.TeamProblemListResponse, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(req.TeamID, userID, ""); err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
-	}
 
 	query := config.DB.Model(&models.TeamProblem{}).Where("team_id = ?", req.TeamID)
 
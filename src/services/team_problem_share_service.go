@@ -0,0 +1,398 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// getTeamProblem 获取团队私有题目
+func getTeamProblem(problemID uint64) (*models.TeamProblem, error) {
+	var problem models.TeamProblem
+	if err := config.DB.First(&problem, problemID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("题目不存在")
+		}
+		return nil, err
+	}
+	return &problem, nil
+}
+
+// isTeamProblemSharedWithUser 判断题目是否共享给了用户所在的任一团队
+func isTeamProblemSharedWithUser(problemID uint64, userID uint64) (bool, error) {
+	var count int64
+	if err := config.DB.Table("team_problem_shares s").
+		Joins("JOIN team_members tm ON tm.team_id = s.team_id").
+		Where("s.problem_id = ? AND tm.user_id = ?", problemID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ShareTeamProblem 将团队私有题目共享给其他团队，重复共享时更新共享方式
+func ShareTeamProblem(problemID uint64, req *models.ShareTeamProblemRequest, operatorID uint64) error {
+	problem, err := getTeamProblem(problemID)
+	if err != nil {
+		return err
+	}
+	if err := checkTeamWritable(problem.TeamID); err != nil {
+		return err
+	}
+	if _, err := AuthorizeTeam(problem.TeamID, operatorID, models.TeamPermManageProblems); err != nil {
+		return err
+	}
+
+	if req.TeamID == problem.TeamID {
+		return errors.New("不能共享给题目所属团队")
+	}
+	var team models.Team
+	if err := config.DB.Select("id").First(&team, req.TeamID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("目标团队不存在")
+		}
+		return err
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = models.TeamProblemShareReadonly
+	}
+
+	return config.DB.Exec(`
+		INSERT INTO team_problem_shares (problem_id, team_id, mode, shared_by)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		mode = VALUES(mode),
+		shared_by = VALUES(shared_by)
+	`, problemID, req.TeamID, mode, operatorID).Error
+}
+
+// UnshareTeamProblem 取消对某个团队的共享，已复制的题目不受影响
+func UnshareTeamProblem(problemID uint64, teamID uint64, operatorID uint64) error {
+	problem, err := getTeamProblem(problemID)
+	if err != nil {
+		return err
+	}
+	if _, err := AuthorizeTeam(problem.TeamID, operatorID, models.TeamPermManageProblems); err != nil {
+		return err
+	}
+
+	result := config.DB.Where("problem_id = ? AND team_id = ?", problemID, teamID).Delete(&models.TeamProblemShare{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("题目未共享给该团队")
+	}
+	return nil
+}
+
+// GetTeamProblemShares 获取题目的共享记录
+func GetTeamProblemShares(problemID uint64, operatorID uint64) ([]models.TeamProblemShareInfo, error) {
+	problem, err := getTeamProblem(problemID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := HasTeamPermission(problem.TeamID, operatorID, models.TeamPermManageProblems)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("权限不足")
+	}
+
+	shares := make([]models.TeamProblemShareInfo, 0)
+	if err := config.DB.Table("team_problem_shares s").
+		Select("s.*, t.name as team_name").
+		Joins("JOIN teams t ON t.id = s.team_id").
+		Where("s.problem_id = ?", problemID).
+		Order("s.created_at DESC").
+		Scan(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// GetSharedTeamProblems 获取其他团队共享给本团队的题目
+func GetSharedTeamProblems(teamID uint64, userID uint64) ([]models.SharedTeamProblemInfo, error) {
+	if _, err := AuthorizeTeam(teamID, userID, ""); err != nil {
+		return nil, err
+	}
+
+	problems := make([]models.SharedTeamProblemInfo, 0)
+	if err := config.DB.Table("team_problem_shares s").
+		Select("p.*, s.mode, t.name as source_team, u.username as shared_by_name").
+		Joins("JOIN team_problems p ON p.id = s.problem_id").
+		Joins("JOIN teams t ON t.id = p.team_id").
+		Joins("LEFT JOIN users u ON u.id = s.shared_by").
+		Where("s.team_id = ?", teamID).
+		Order("s.created_at DESC").
+		Scan(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// CopySharedTeamProblem 将允许复制的共享题目连同测试用例复制到本团队
+func CopySharedTeamProblem(problemID uint64, req *models.CopyTeamProblemRequest, operatorID uint64) (uint64, error) {
+	source, err := getTeamProblem(problemID)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkTeamWritable(req.TeamID); err != nil {
+		return 0, err
+	}
+	if _, err := AuthorizeTeam(req.TeamID, operatorID, models.TeamPermManageProblems); err != nil {
+		return 0, err
+	}
+
+	var share models.TeamProblemShare
+	if err := config.DB.Where("problem_id = ? AND team_id = ?", problemID, req.TeamID).First(&share).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.New("题目未共享给该团队")
+		}
+		return 0, err
+	}
+	if share.Mode != models.TeamProblemShareCopy {
+		return 0, errors.New("该题目仅共享为只读，不能复制")
+	}
+
+	var testCases []models.TeamProblemTestCase
+	if err := config.DB.Where("problem_id = ?", problemID).Order("id").Find(&testCases).Error; err != nil {
+		return 0, err
+	}
+
+	copied := *source
+	copied.ID = 0
+	copied.TeamID = req.TeamID
+	copied.CopiedFrom = &source.ID
+	copied.CreatedBy = operatorID
+	copied.CreatedAt = time.Now()
+	copied.UpdatedAt = time.Now()
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&copied).Error; err != nil {
+			return err
+		}
+		for _, tc := range testCases {
+			testCase := &models.TeamProblemTestCase{
+				ProblemID:  copied.ID,
+				InputData:  tc.InputData,
+				OutputData: tc.OutputData,
+				IsSample:   tc.IsSample,
+				CreatedAt:  time.Now(),
+			}
+			if err := tx.Create(testCase).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return copied.ID, nil
+}
+
+// NominateTeamProblem 推荐团队私有题目进入公共题库，由网站管理员审核
+func NominateTeamProblem(problemID uint64, req *models.NominateTeamProblemRequest, operatorID uint64) (*models.TeamProblemPromotion, error) {
+	problem, err := getTeamProblem(problemID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := AuthorizeTeam(problem.TeamID, operatorID, models.TeamPermManageProblems); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.TeamProblemPromotion{}).
+		Where("team_problem_id = ? AND status IN ?", problemID,
+			[]string{models.ProblemPromotionPending, models.ProblemPromotionApproved}).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("该题目已推荐或已进入公共题库")
+	}
+
+	promotion := &models.TeamProblemPromotion{
+		TeamProblemID: problemID,
+		TeamID:        problem.TeamID,
+		RequestedBy:   operatorID,
+		Note:          req.Note,
+		Status:        models.ProblemPromotionPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := config.DB.Create(promotion).Error; err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// GetProblemPromotions 获取推荐申请列表，仅网站管理员可用
+func GetProblemPromotions(req *models.ProblemPromotionListRequest) ([]models.TeamProblemPromotionInfo, error) {
+	query := config.DB.Table("team_problem_promotions pp").
+		Select("pp.*, p.title, t.name as team_name, u.username as requester_name").
+		Joins("JOIN team_problems p ON p.id = pp.team_problem_id").
+		Joins("JOIN teams t ON t.id = pp.team_id").
+		Joins("LEFT JOIN users u ON u.id = pp.requested_by")
+	if req.Status != "" {
+		query = query.Where("pp.status = ?", req.Status)
+	}
+
+	promotions := make([]models.TeamProblemPromotionInfo, 0)
+	if err := query.Order("pp.created_at DESC").Scan(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// ReviewProblemPromotion 审核推荐申请。通过时将题目复制到公共题库，保留原作者，
+// 并把数据库中的测试用例转换为文件存储的测试用例
+func ReviewProblemPromotion(promotionID uint64, req *models.ReviewProblemPromotionRequest, adminID uint64) error {
+	var promotion models.TeamProblemPromotion
+	if err := config.DB.First(&promotion, promotionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("推荐申请不存在")
+		}
+		return err
+	}
+	if promotion.Status != models.ProblemPromotionPending {
+		return errors.New("该申请已被处理")
+	}
+
+	source, err := getTeamProblem(promotion.TeamProblemID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !*req.Approve {
+		if err := claimProblemPromotion(config.DB, promotionID, models.ProblemPromotionRejected, req.ReviewNote, adminID, now); err != nil {
+			return err
+		}
+		content := fmt.Sprintf("您推荐的题目 %s 未通过审核", source.Title)
+		if req.ReviewNote != "" {
+			content += "\n审核意见：" + req.ReviewNote
+		}
+		return CreateMessage(&adminID, promotion.RequestedBy, models.MessageTypeTeamNotice, "题目推荐结果 - "+source.Title, content)
+	}
+
+	system, err := GetDifficultySystem()
+	if err != nil {
+		return err
+	}
+	difficulty := req.Difficulty
+	if difficulty == "" {
+		difficulty = models.DifficultyNormalUnrated
+	}
+	if !isValidDifficulty(system.CurrentSystem, difficulty) {
+		return fmt.Errorf("无效的难度等级: %s", difficulty)
+	}
+
+	var team models.Team
+	if err := config.DB.Select("id, name").First(&team, promotion.TeamID).Error; err != nil {
+		return err
+	}
+
+	var testCases []models.TeamProblemTestCase
+	if err := config.DB.Where("problem_id = ?", source.ID).Order("id").Find(&testCases).Error; err != nil {
+		return err
+	}
+
+	problem := &models.Problem{
+		Title:             source.Title,
+		Description:       source.Description,
+		InputDescription:  source.InputDescription,
+		OutputDescription: source.OutputDescription,
+		SampleCases:       source.SampleCases,
+		Hint:              source.Hint,
+		Source:            "团队 " + team.Name,
+		DifficultySystem:  system.CurrentSystem,
+		Difficulty:        difficulty,
+		TimeLimit:         source.TimeLimit,
+		MemoryLimit:       source.MemoryLimit,
+		IsPublic:          req.IsPublic,
+		CreatedBy:         source.CreatedBy, // 保留原作者
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	var testCaseDir string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// 先在事务中认领申请，并发的审核请求只有一个能继续创建题目
+		if err := claimProblemPromotion(tx, promotionID, models.ProblemPromotionApproved, req.ReviewNote, adminID, now); err != nil {
+			return err
+		}
+		if err := tx.Create(problem).Error; err != nil {
+			return err
+		}
+
+		testCaseDir = filepath.Join("data", "testcases", fmt.Sprintf("problem_%d", problem.ID))
+		if err := os.MkdirAll(testCaseDir, 0755); err != nil {
+			return err
+		}
+		for i, tc := range testCases {
+			// 生成唯一的文件名，与上传的测试用例保持一致
+			timestamp := time.Now().UnixNano() + int64(i)
+			inputFile := filepath.Join(testCaseDir, fmt.Sprintf("input_%d.txt", timestamp))
+			outputFile := filepath.Join(testCaseDir, fmt.Sprintf("output_%d.txt", timestamp))
+			if err := os.WriteFile(inputFile, []byte(tc.InputData), 0644); err != nil {
+				return fmt.Errorf("写入输入文件失败: %v", err)
+			}
+			if err := os.WriteFile(outputFile, []byte(tc.OutputData), 0644); err != nil {
+				return fmt.Errorf("写入输出文件失败: %v", err)
+			}
+			if err := tx.Create(&models.TestCase{
+				ProblemID:  problem.ID,
+				InputFile:  inputFile,
+				OutputFile: outputFile,
+				CreatedAt:  now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&promotion).Update("problem_id", problem.ID).Error
+	})
+	if err != nil {
+		// 事务回滚后清理已写入的测试用例文件
+		if testCaseDir != "" {
+			os.RemoveAll(testCaseDir)
+		}
+		return err
+	}
+
+	content := fmt.Sprintf("您推荐的题目 %s 已通过审核，加入公共题库（题目编号：%d）", source.Title, problem.ID)
+	if req.ReviewNote != "" {
+		content += "\n审核意见：" + req.ReviewNote
+	}
+	return CreateMessage(&adminID, promotion.RequestedBy, models.MessageTypeTeamNotice, "题目推荐结果 - "+source.Title, content)
+}
+
+// claimProblemPromotion 仅当申请仍在待审核状态时写入审核结果，已被处理时返回错误
+func claimProblemPromotion(db *gorm.DB, promotionID uint64, status string, reviewNote string, adminID uint64, reviewedAt time.Time) error {
+	result := db.Model(&models.TeamProblemPromotion{}).
+		Where("id = ? AND status = ?", promotionID, models.ProblemPromotionPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": adminID,
+			"review_note": reviewNote,
+			"reviewed_at": reviewedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该申请已被处理")
+	}
+	return nil
+}
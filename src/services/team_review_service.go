@@ -0,0 +1,193 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// getReviewableSubmission 获取作业提交记录及其所属作业，并校验操作者是否拥有批阅权限
+func getReviewableSubmission(submissionID uint64, operatorID uint64) (*models.Submission, *models.TeamAssignment, error) {
+	var submission models.Submission
+	if err := config.DB.First(&submission, submissionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errors.New("提交记录不存在")
+		}
+		return nil, nil, err
+	}
+	if submission.AssignmentID == nil {
+		return nil, nil, errors.New("该提交不属于任何作业")
+	}
+
+	var assignment models.TeamAssignment
+	if err := config.DB.First(&assignment, *submission.AssignmentID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// 检查操作者权限
+	if _, err := AuthorizeTeam(assignment.TeamID, operatorID, models.TeamPermGrade); err != nil {
+		return nil, nil, err
+	}
+
+	return &submission, &assignment, nil
+}
+
+// CanViewAssignmentSubmission 判断用户是否可以查看他人的作业提交（在作业所属团队拥有查看全部提交权限）
+func CanViewAssignmentSubmission(submission *models.Submission, userID uint64) bool {
+	if submission.AssignmentID == nil {
+		return false
+	}
+
+	var assignment models.TeamAssignment
+	if err := config.DB.First(&assignment, *submission.AssignmentID).Error; err != nil {
+		return false
+	}
+
+	allowed, err := HasTeamPermission(assignment.TeamID, userID, models.TeamPermViewSubmissions)
+	return err == nil && allowed
+}
+
+// AddSubmissionComment 为作业提交添加代码行评论
+func AddSubmissionComment(submissionID uint64, req *models.AddSubmissionCommentRequest, operatorID uint64) (*models.SubmissionComment, error) {
+	submission, assignment, err := getReviewableSubmission(submissionID, operatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	lineEnd := req.LineEnd
+	if lineEnd == 0 {
+		lineEnd = req.LineStart
+	}
+	lineCount := strings.Count(submission.Code, "\n") + 1
+	if lineEnd < req.LineStart || lineEnd > lineCount {
+		return nil, errors.New("行号超出代码范围")
+	}
+
+	comment := models.SubmissionComment{
+		SubmissionID: submissionID,
+		UserID:       operatorID,
+		LineStart:    req.LineStart,
+		LineEnd:      lineEnd,
+		Content:      req.Content,
+	}
+	if err := config.DB.Create(&comment).Error; err != nil {
+		return nil, err
+	}
+
+	// 通知提交者
+	if submission.UserID != operatorID {
+		content := fmt.Sprintf("您在作业 %s 中的提交 #%d 收到了新的代码评论（第 %d 行）", assignment.Title, submissionID, req.LineStart)
+		if err := CreateMessage(&operatorID, submission.UserID, models.MessageTypeTeamNotice, "作业批阅通知 - "+assignment.Title, content); err != nil {
+			return nil, err
+		}
+	}
+
+	return &comment, nil
+}
+
+// DeleteSubmissionComment 删除代码行评论
+func DeleteSubmissionComment(submissionID uint64, commentID uint64, operatorID uint64) error {
+	if _, _, err := getReviewableSubmission(submissionID, operatorID); err != nil {
+		return err
+	}
+
+	result := config.DB.Where("id = ? AND submission_id = ?", commentID, submissionID).
+		Delete(&models.SubmissionComment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("评论不存在")
+	}
+
+	return nil
+}
+
+// GradeSubmission 对作业提交进行人工评分并标记批阅状态
+func GradeSubmission(submissionID uint64, req *models.GradeSubmissionRequest, operatorID uint64) error {
+	submission, assignment, err := getReviewableSubmission(submissionID, operatorID)
+	if err != nil {
+		return err
+	}
+
+	// 人工评分不能超过题目分值
+	if req.ManualScore != nil {
+		var problem models.TeamAssignmentProblem
+		if err := config.DB.Where("assignment_id = ? AND problem_id = ?", assignment.ID, submission.ProblemID).
+			First(&problem).Error; err != nil {
+			return err
+		}
+		if *req.ManualScore > problem.Score {
+			return fmt.Errorf("人工评分不能超过题目分值 %d", problem.Score)
+		}
+	}
+
+	reviewed := true
+	if req.Reviewed != nil {
+		reviewed = *req.Reviewed
+	}
+	var reviewedAt *time.Time
+	if reviewed {
+		now := time.Now()
+		reviewedAt = &now
+	}
+
+	err = config.DB.Exec(`
+		INSERT INTO submission_reviews (submission_id, reviewer_id, manual_score, rationale, reviewed, reviewed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		reviewer_id = VALUES(reviewer_id),
+		manual_score = VALUES(manual_score),
+		rationale = VALUES(rationale),
+		reviewed = VALUES(reviewed),
+		reviewed_at = VALUES(reviewed_at),
+		updated_at = CURRENT_TIMESTAMP
+	`, submissionID, operatorID, req.ManualScore, req.Rationale, reviewed, reviewedAt).Error
+	if err != nil {
+		return err
+	}
+
+	// 通知提交者
+	if !reviewed || submission.UserID == operatorID {
+		return nil
+	}
+	content := fmt.Sprintf("您在作业 %s 中的提交 #%d 已批阅", assignment.Title, submissionID)
+	if req.ManualScore != nil {
+		content += fmt.Sprintf("，得分：%d", *req.ManualScore)
+	}
+	if req.Rationale != "" {
+		content += "\n评语：" + req.Rationale
+	}
+	return CreateMessage(&operatorID, submission.UserID, models.MessageTypeTeamNotice, "作业批阅通知 - "+assignment.Title, content)
+}
+
+// GetSubmissionFeedback 获取作业提交的批阅反馈
+func GetSubmissionFeedback(submissionID uint64) (*models.SubmissionFeedback, error) {
+	feedback := &models.SubmissionFeedback{
+		Comments: make([]models.SubmissionComment, 0),
+	}
+
+	var review models.SubmissionReview
+	err := config.DB.Where("submission_id = ?", submissionID).First(&review).Error
+	if err == nil {
+		feedback.Review = &review
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err := config.DB.Table("submission_comments sc").
+		Select("sc.*, u.username").
+		Joins("JOIN users u ON u.id = sc.user_id").
+		Where("sc.submission_id = ?", submissionID).
+		Order("sc.line_start, sc.id").
+		Scan(&feedback.Comments).Error; err != nil {
+		return nil, err
+	}
+
+	return feedback, nil
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// 自定义角色标识：小写字母开头，由小写字母、数字和下划线组成
+var teamRolePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+
+// AuthorizeTeam 检查用户是否为团队成员，并在 permission 非空时检查是否拥有该权限，返回用户在团队中的角色。
+// 所有团队相关的权限检查都应通过该方法完成；除查看提交外的权限都会修改团队数据，已归档的团队会被拒绝。
+// 团队所属组织的管理员即使不是团队成员也拥有全部权限，角色为 org_admin
+func AuthorizeTeam(teamID uint64, userID uint64, permission string) (string, error) {
+	role, err := getTeamEffectiveRole(teamID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", errors.New("您不是团队成员")
+	}
+	if permission == "" {
+		return role, nil
+	}
+
+	allowed, err := TeamRoleHasPermission(teamID, role, permission)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "", errors.New("权限不足")
+	}
+	if permission != models.TeamPermViewSubmissions {
+		if err := checkTeamWritable(teamID); err != nil {
+			return "", err
+		}
+	}
+	return role, nil
+}
+
+// HasTeamPermission 判断用户在团队中是否拥有指定权限，非团队成员返回 false；permission 为空时只判断是否为团队成员
+func HasTeamPermission(teamID uint64, userID uint64, permission string) (bool, error) {
+	role, err := getTeamEffectiveRole(teamID, userID)
+	if err != nil || role == "" {
+		return false, err
+	}
+	if permission == "" {
+		return true, nil
+	}
+	return TeamRoleHasPermission(teamID, role, permission)
+}
+
+// getTeamEffectiveRole 获取用户在团队中生效的角色：所有者保持不变，其他情况下组织管理员优先于成员角色
+func getTeamEffectiveRole(teamID uint64, userID uint64) (string, error) {
+	role, err := GetTeamUserRole(teamID, userID)
+	if err != nil || role == models.TeamRoleOwner {
+		return role, err
+	}
+
+	isOrgAdmin, err := isTeamOrganizationAdmin(teamID, userID)
+	if err != nil {
+		return "", err
+	}
+	if isOrgAdmin {
+		return models.TeamRoleOrgAdmin, nil
+	}
+	if role == models.TeamRoleOrgAdmin {
+		// 成员记录中的 org_admin 不是真正的组织管理员，按普通成员处理
+		return models.TeamRoleMember, nil
+	}
+	return role, nil
+}
+
+// TeamRoleHasPermission 判断团队中的某个角色是否拥有指定权限
+func TeamRoleHasPermission(teamID uint64, role string, permission string) (bool, error) {
+	if role == models.TeamRoleOwner || role == models.TeamRoleOrgAdmin {
+		return true, nil
+	}
+
+	permissions, err := getTeamRolePermissionMap(teamID)
+	if err != nil {
+		return false, err
+	}
+	return containsString(permissions[role], permission), nil
+}
+
+// getTeamRolePermissionMap 获取团队各角色的权限，团队自定义配置覆盖内置角色的默认权限
+func getTeamRolePermissionMap(teamID uint64) (map[string][]string, error) {
+	roles, err := listTeamRoles(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		result[role.Role] = role.Permissions
+	}
+	return result, nil
+}
+
+// listTeamRoles 获取团队的全部角色（内置角色在前，自定义角色在后）
+func listTeamRoles(teamID uint64) ([]models.TeamRoleInfo, error) {
+	var custom []models.TeamRole
+	if err := config.DB.Where("team_id = ?", teamID).Order("role").Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]models.TeamRole, len(custom))
+	for _, role := range custom {
+		overrides[role.Role] = role
+	}
+
+	roles := make([]models.TeamRoleInfo, 0, len(models.DefaultTeamRoles)+len(custom))
+	for _, role := range models.DefaultTeamRoles {
+		if override, ok := overrides[role.Role]; ok && role.Role != models.TeamRoleOwner {
+			role.Name = override.Name
+			role.Permissions = splitPermissions(override.Permissions)
+			delete(overrides, role.Role)
+		}
+		roles = append(roles, role)
+	}
+	for _, role := range custom {
+		if _, ok := overrides[role.Role]; !ok || isBuiltInTeamRole(role.Role) || isReservedTeamRole(role.Role) {
+			continue
+		}
+		roles = append(roles, models.TeamRoleInfo{
+			Role:        role.Role,
+			Name:        role.Name,
+			Permissions: splitPermissions(role.Permissions),
+		})
+	}
+
+	return roles, nil
+}
+
+// splitPermissions 解析逗号分隔的权限列表
+func splitPermissions(permissions string) []string {
+	result := make([]string, 0)
+	for _, p := range strings.Split(permissions, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// isBuiltInTeamRole 判断是否为内置角色
+func isBuiltInTeamRole(role string) bool {
+	for _, r := range models.DefaultTeamRoles {
+		if r.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// isReservedTeamRole 判断是否为系统保留的角色标识。org_admin 由组织管理员身份自动获得且拥有全部权限，
+// 不能作为团队角色保存或分配给成员
+func isReservedTeamRole(role string) bool {
+	return role == models.TeamRoleOwner || role == models.TeamRoleOrgAdmin
+}
+
+// teamRoleExists 判断团队中是否存在可分配的该角色
+func teamRoleExists(teamID uint64, role string) (bool, error) {
+	if role == models.TeamRoleOrgAdmin {
+		return false, nil
+	}
+	if isBuiltInTeamRole(role) {
+		return true, nil
+	}
+	var count int64
+	if err := config.DB.Model(&models.TeamRole{}).Where("team_id = ? AND role = ?", teamID, role).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetTeamRoles 获取团队角色列表
+func GetTeamRoles(teamID uint64, userID uint64) ([]models.TeamRoleInfo, error) {
+	if _, err := AuthorizeTeam(teamID, userID, ""); err != nil {
+		return nil, err
+	}
+	return listTeamRoles(teamID)
+}
+
+// SaveTeamRole 创建自定义角色或修改角色权限，只有团队所有者可以操作
+func SaveTeamRole(teamID uint64, role string, req *models.SaveTeamRoleRequest, operatorID uint64) error {
+	operatorRole, err := AuthorizeTeam(teamID, operatorID, "")
+	if err != nil {
+		return err
+	}
+	if operatorRole != models.TeamRoleOwner {
+		return errors.New("只有团队所有者可以配置角色")
+	}
+	if err := checkTeamWritable(teamID); err != nil {
+		return err
+	}
+
+	if role == models.TeamRoleOwner {
+		return errors.New("不能修改所有者角色")
+	}
+	if isReservedTeamRole(role) {
+		return errors.New("该角色标识为系统保留")
+	}
+	if !teamRolePattern.MatchString(role) {
+		return errors.New("无效的角色标识")
+	}
+
+	return config.DB.Exec(`
+		INSERT INTO team_roles (team_id, role, name, permissions)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		name = VALUES(name),
+		permissions = VALUES(permissions),
+		updated_at = CURRENT_TIMESTAMP
+	`, teamID, role, req.Name, strings.Join(req.Permissions, ",")).Error
+}
+
+// DeleteTeamRole 删除自定义角色；对内置角色则恢复默认权限
+func DeleteTeamRole(teamID uint64, role string, operatorID uint64) error {
+	operatorRole, err := AuthorizeTeam(teamID, operatorID, "")
+	if err != nil {
+		return err
+	}
+	if operatorRole != models.TeamRoleOwner {
+		return errors.New("只有团队所有者可以配置角色")
+	}
+	if err := checkTeamWritable(teamID); err != nil {
+		return err
+	}
+
+	// 自定义角色仍有成员使用时不能删除
+	if !isBuiltInTeamRole(role) {
+		var count int64
+		if err := config.DB.Model(&models.TeamMember{}).Where("team_id = ? AND role = ?", teamID, role).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("该角色仍有成员使用，无法删除")
+		}
+	}
+
+	result := config.DB.Where("team_id = ? AND role = ?", teamID, role).Delete(&models.TeamRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("角色不存在或未修改过")
+	}
+	return nil
+}
+
+// containsString 判断字符串切片中是否包含指定值
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
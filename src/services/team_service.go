@@ -37,7 +37,7 @@ func CreateTeam(req *models.CreateTeamRequest, creatorID uint64) (uint64, error)
 		member := &models.TeamMember{
 			TeamID:   team.ID,
 			UserID:   creatorID,
-			Role:     "owner",
+			Role:     models.TeamRoleOwner,
 			JoinedAt: time.Now(),
 		}
 		return tx.Create(member).Error
@@ -47,13 +47,9 @@ func CreateTeam(req *models.CreateTeamRequest, creatorID uint64) (uint64, error)
 // UpdateTeam 更新团队信息
 func UpdateTeam(teamID uint64, req *models.UpdateTeamRequest, userID uint64) error {
 	// 检查用户权限
-	role, err := GetTeamUserRole(teamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(teamID, userID, models.TeamPermManageTeam); err != nil {
 		return err
 	}
-	if role != "owner" && role != "admin" {
-		return errors.New("权限不足")
-	}
 
 	updates := make(map[string]interface{})
 	if req.Name != "" {
@@ -67,59 +63,9 @@ func UpdateTeam(teamID uint64, req *models.UpdateTeamRequest, userID uint64) err
 	return config.DB.Model(&models.Team{}).Where("id = ?", teamID).Updates(updates).Error
 }
 
-// DeleteTeam 删除团队
+// DeleteTeam 删除团队，团队不会被物理删除，而是归档保留提交记录和成绩表
 func DeleteTeam(teamID uint64, userID uint64) error {
-	// 检查用户权限
-	role, err := GetTeamUserRole(teamID, userID)
-	if err != nil {
-		return err
-	}
-	if role != "owner" {
-		return errors.New("只有团队所有者可以删除团队")
-	}
-
-	return config.DB.Transaction(func(tx *gorm.DB) error {
-		// 删除团队成员
-		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
-			return err
-		}
-
-		// 删除团队作业和相关题目
-		var assignments []models.TeamAssignment
-		if err := tx.Where("team_id = ?", teamID).Find(&assignments).Error; err != nil {
-			return err
-		}
-		for _, assignment := range assignments {
-			if err := tx.Where("assignment_id = ?", assignment.ID).Delete(&models.TeamAssignmentProblem{}).Error; err != nil {
-				return err
-			}
-		}
-		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamAssignment{}).Error; err != nil {
-			return err
-		}
-
-		// 删除团队题单和相关题目
-		var lists []models.TeamProblemList
-		if err := tx.Where("team_id = ?", teamID).Find(&lists).Error; err != nil {
-			return err
-		}
-		for _, list := range lists {
-			if err := tx.Where("list_id = ?", list.ID).Delete(&models.TeamProblemListItem{}).Error; err != nil {
-				return err
-			}
-		}
-		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamProblemList{}).Error; err != nil {
-			return err
-		}
-
-		// 删除团队邀请
-		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamInvitation{}).Error; err != nil {
-			return err
-		}
-
-		// 删除团队
-		return tx.Delete(&models.Team{}, teamID).Error
-	})
+	return ArchiveTeam(teamID, userID)
 }
 
 // GetTeamDetail 获取团队详情
@@ -145,6 +91,18 @@ func GetTeamDetail(teamID uint64, userID uint64) (*models.TeamDetail, error) {
 		role, _ := GetTeamUserRole(teamID, userID)
 		detail.UserRole = role
 		detail.IsJoined = role != ""
+
+		// 团队动态和统计面板的入口，按用户权限返回
+		if _, err := AuthorizeTeam(teamID, userID, ""); err == nil {
+			detail.ActivityURL = fmt.Sprintf("/teams/%d/activity", teamID)
+		}
+		canViewStats, err := HasTeamPermission(teamID, userID, models.TeamPermViewSubmissions)
+		if err != nil {
+			return nil, err
+		}
+		if canViewStats {
+			detail.StatsURL = fmt.Sprintf("/teams/%d/stats", teamID)
+		}
 	}
 
 	// 获取创建者基本信息
@@ -209,6 +167,18 @@ func GetTeamList(req *models.TeamListRequest, userID uint64) (*models.TeamListRe
 		query = query.Where("teams.name LIKE ? OR teams.description LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%")
 	}
 
+	// 按所属组织筛选
+	if req.OrgID > 0 {
+		orgIDs := []uint64{req.OrgID}
+		if req.IncludeSubOrgs {
+			var err error
+			if orgIDs, err = getOrganizationDescendantIDs(req.OrgID); err != nil {
+				return nil, err
+			}
+		}
+		query = query.Where("teams.org_id IN ?", orgIDs)
+	}
+
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, err
@@ -268,15 +238,47 @@ func GetTeamUserRole(teamID uint64, userID uint64) (string, error) {
 	return member.Role, nil
 }
 
-// CreateTeamInvitation 创建团队邀请
-func CreateTeamInvitation(teamID uint64, userID uint64) (*models.TeamInvitation, error) {
+// CreateTeamInvitation 创建团队邀请，可设置使用次数、过期时间、加入后的角色和限定邮箱
+func CreateTeamInvitation(teamID uint64, req *models.CreateTeamInvitationRequest, userID uint64) (*models.TeamInvitation, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(teamID, userID)
+	operatorRole, err := AuthorizeTeam(teamID, userID, models.TeamPermManageMembers)
 	if err != nil {
 		return nil, err
 	}
-	if role != "owner" && role != "admin" {
-		return nil, errors.New("权限不足")
+
+	// 验证加入后的角色，所有者只能通过转让产生
+	role := req.Role
+	if role == "" {
+		role = models.TeamRoleMember
+	}
+	if role == models.TeamRoleOwner {
+		return nil, errors.New("无效的角色")
+	}
+	exists, err := teamRoleExists(teamID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("无效的角色")
+	}
+
+	// 非所有者（组织管理员除外）不能通过邀请授予成员管理权限
+	if operatorRole != models.TeamRoleOwner && operatorRole != models.TeamRoleOrgAdmin {
+		canManage, err := TeamRoleHasPermission(teamID, role, models.TeamPermManageMembers)
+		if err != nil {
+			return nil, err
+		}
+		if canManage {
+			return nil, errors.New("只有团队所有者可以创建授予成员管理权限的邀请")
+		}
+	}
+
+	expiresAt := time.Now().Add(7 * 24 * time.Hour) // 默认7天有效期
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return nil, errors.New("过期时间必须晚于当前时间")
+		}
+		expiresAt = *req.ExpiresAt
 	}
 
 	// 生成邀请码
@@ -285,9 +287,12 @@ func CreateTeamInvitation(teamID uint64, userID uint64) (*models.TeamInvitation,
 	invitation := &models.TeamInvitation{
 		TeamID:    teamID,
 		Code:      code,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour), // 7天有效期
+		ExpiresAt: expiresAt,
 		CreatedBy: userID,
 		CreatedAt: time.Now(),
+		MaxUses:   req.MaxUses,
+		Role:      role,
+		Email:     strings.ToLower(req.Email),
 	}
 
 	if err := config.DB.Create(invitation).Error; err != nil {
@@ -300,12 +305,26 @@ func CreateTeamInvitation(teamID uint64, userID uint64) (*models.TeamInvitation,
 // JoinTeamByInvitation 通过邀请码加入团队
 func JoinTeamByInvitation(code string, userID uint64) error {
 	var invitation models.TeamInvitation
-	if err := config.DB.Where("code = ? AND expires_at > ?", code, time.Now()).First(&invitation).Error; err != nil {
+	if err := config.DB.Where("code = ? AND expires_at > ? AND revoked_at IS NULL", code, time.Now()).First(&invitation).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.New("无效或已过期的邀请码")
 		}
 		return err
 	}
+	if err := checkTeamWritable(invitation.TeamID); err != nil {
+		return err
+	}
+
+	// 检查邀请是否限定了邮箱
+	if invitation.Email != "" {
+		var user models.User
+		if err := config.DB.Select("id, email").First(&user, userID).Error; err != nil {
+			return err
+		}
+		if !strings.EqualFold(user.Email, invitation.Email) {
+			return errors.New("该邀请码仅限指定邮箱的用户使用")
+		}
+	}
 
 	// 检查用户是否已经是团队成员
 	var count int64
@@ -316,15 +335,91 @@ func JoinTeamByInvitation(code string, userID uint64) error {
 		return errors.New("您已经是团队成员")
 	}
 
-	// 添加用户为团队成员
-	member := &models.TeamMember{
-		TeamID:   invitation.TeamID,
-		UserID:   userID,
-		Role:     "member",
-		JoinedAt: time.Now(),
+	// 邀请指定的角色可能已被删除，此时以成员身份加入
+	role := invitation.Role
+	exists, err := teamRoleExists(invitation.TeamID, role)
+	if err != nil {
+		return err
+	}
+	if !exists || role == models.TeamRoleOwner {
+		role = models.TeamRoleMember
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// 原子地占用一次使用次数
+		result := tx.Model(&models.TeamInvitation{}).
+			Where("id = ? AND (max_uses IS NULL OR used_count < max_uses)", invitation.ID).
+			Update("used_count", gorm.Expr("used_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("邀请码使用次数已达上限")
+		}
+
+		// 添加用户为团队成员，并记录使用的邀请
+		member := &models.TeamMember{
+			TeamID:       invitation.TeamID,
+			UserID:       userID,
+			Role:         role,
+			JoinedAt:     time.Now(),
+			InvitationID: &invitation.ID,
+		}
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		return recordTeamEvent(tx, invitation.TeamID, &userID, models.TeamEventMemberJoined, userID, "通过邀请码加入了团队")
+	})
+}
+
+// GetTeamInvitations 获取团队邀请列表及使用情况
+func GetTeamInvitations(teamID uint64, req *models.TeamInvitationListRequest, userID uint64) ([]models.TeamInvitationInfo, error) {
+	allowed, err := HasTeamPermission(teamID, userID, models.TeamPermManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("权限不足")
+	}
+
+	query := config.DB.Table("team_invitations ti").
+		Select("ti.*, u.username as creator_name").
+		Joins("LEFT JOIN users u ON u.id = ti.created_by").
+		Where("ti.team_id = ?", teamID)
+	if !req.All {
+		query = query.Where("ti.revoked_at IS NULL AND ti.expires_at > ? AND (ti.max_uses IS NULL OR ti.used_count < ti.max_uses)", time.Now())
+	}
+
+	invitations := make([]models.TeamInvitationInfo, 0)
+	if err := query.Order("ti.created_at DESC").Scan(&invitations).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range invitations {
+		inv := &invitations[i]
+		inv.Active = inv.RevokedAt == nil && inv.ExpiresAt.After(now) &&
+			(inv.MaxUses == nil || inv.UsedCount < *inv.MaxUses)
+	}
+	return invitations, nil
+}
+
+// RevokeTeamInvitation 撤销团队邀请，已通过该邀请加入的成员不受影响
+func RevokeTeamInvitation(teamID uint64, invitationID uint64, userID uint64) error {
+	if _, err := AuthorizeTeam(teamID, userID, models.TeamPermManageMembers); err != nil {
+		return err
 	}
 
-	return config.DB.Create(member).Error
+	result := config.DB.Model(&models.TeamInvitation{}).
+		Where("id = ? AND team_id = ? AND revoked_at IS NULL", invitationID, teamID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("邀请不存在或已撤销")
+	}
+	return nil
 }
 
 // generateInviteCode 生成邀请码
@@ -343,18 +438,22 @@ func generateInviteCode() string {
 // CreateAssignment 创建团队作业
 func CreateAssignment(req *models.CreateAssignmentRequest, userID uint64) (uint64, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(req.TeamID, userID, models.TeamPermCreateAssignment); err != nil {
 		return 0, err
 	}
-	if role != "owner" && role != "admin" {
-		return 0, errors.New("权限不足")
-	}
 
 	// 验证时间
 	if req.StartTime.After(req.EndTime) {
 		return 0, errors.New("开始时间不能晚于结束时间")
 	}
+	if req.LateCutoff != nil && req.LateCutoff.Before(req.EndTime) {
+		return 0, errors.New("迟交截止时间不能早于结束时间")
+	}
+
+	latePenaltyUnit := req.LatePenaltyUnit
+	if latePenaltyUnit == "" {
+		latePenaltyUnit = models.LatePenaltyUnitDay
+	}
 
 	// 验证题目
 	for _, problem := range req.Problems {
@@ -388,11 +487,17 @@ func CreateAssignment(req *models.CreateAssignmentRequest, userID uint64) (uint6
 		CreatedBy:   userID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+
+		AllowLate:          req.AllowLate,
+		LatePenaltyPercent: req.LatePenaltyPercent,
+		LatePenaltyUnit:    latePenaltyUnit,
+		LateCutoff:         req.LateCutoff,
 	}
+	applyPublishSchedule(assignment, req.Hidden, req.PublishAt)
 
-	return assignment.ID, config.DB.Transaction(func(tx *gorm.DB) error {
-		// 创建作业
-		if err := tx.Create(assignment).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// 创建作业，立即发布时同时通知团队成员
+		if err := insertAssignment(tx, assignment, &userID); err != nil {
 			return err
 		}
 
@@ -418,6 +523,11 @@ func CreateAssignment(req *models.CreateAssignmentRequest, userID uint64) (uint6
 
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	return assignment.ID, nil
 }
 
 // UpdateAssignment 更新团队作业
@@ -428,12 +538,19 @@ func UpdateAssignment(assignmentID uint64, req *models.UpdateAssignmentRequest,
 	}
 
 	// 检查用户权限
-	role, err := GetTeamUserRole(assignment.TeamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(assignment.TeamID, userID, models.TeamPermCreateAssignment); err != nil {
 		return err
 	}
-	if role != "owner" && role != "admin" {
-		return errors.New("权限不足")
+
+	// 验证迟交截止时间
+	if req.LateCutoff != nil && !req.ClearLateCutoff {
+		endTime := assignment.EndTime
+		if !req.EndTime.IsZero() {
+			endTime = req.EndTime
+		}
+		if req.LateCutoff.Before(endTime) {
+			return errors.New("迟交截止时间不能早于结束时间")
+		}
 	}
 
 	// 验证题目
@@ -462,6 +579,8 @@ func UpdateAssignment(assignmentID uint64, req *models.UpdateAssignmentRequest,
 	}
 
 	return config.DB.Transaction(func(tx *gorm.DB) error {
+		publishNow := false
+
 		// 更新作业信息
 		updates := make(map[string]interface{})
 		if req.Title != "" {
@@ -476,6 +595,35 @@ func UpdateAssignment(assignmentID uint64, req *models.UpdateAssignmentRequest,
 		if !req.EndTime.IsZero() {
 			updates["end_time"] = req.EndTime
 		}
+		if req.AllowLate != nil {
+			updates["allow_late"] = *req.AllowLate
+		}
+		if req.LatePenaltyPercent != nil {
+			updates["late_penalty_percent"] = *req.LatePenaltyPercent
+		}
+		if req.LatePenaltyUnit != "" {
+			updates["late_penalty_unit"] = req.LatePenaltyUnit
+		}
+		if req.ClearLateCutoff {
+			updates["late_cutoff"] = nil
+		} else if req.LateCutoff != nil {
+			updates["late_cutoff"] = *req.LateCutoff
+		}
+		if req.Hidden != nil || req.PublishAt != nil {
+			hidden := !assignment.IsPublished
+			if req.Hidden != nil {
+				hidden = *req.Hidden
+			}
+			var schedule models.TeamAssignment
+			applyPublishSchedule(&schedule, hidden, req.PublishAt)
+			// 需要立即发布时在更新完成后发布，以便通知团队成员
+			if schedule.IsPublished {
+				publishNow = !assignment.IsPublished
+			} else {
+				updates["is_published"] = false
+				updates["publish_at"] = schedule.PublishAt
+			}
+		}
 		updates["updated_at"] = time.Now()
 
 		if err := tx.Model(&assignment).Updates(updates).Error; err != nil {
@@ -510,19 +658,25 @@ func UpdateAssignment(assignmentID uint64, req *models.UpdateAssignmentRequest,
 			}
 		}
 
-		return nil
+		if !publishNow {
+			return nil
+		}
+		// 重新读取更新后的标题和时间，用于发布通知
+		if err := tx.First(&assignment, assignmentID).Error; err != nil {
+			return err
+		}
+		return publishAssignmentTx(tx, &assignment, &userID)
 	})
 }
 
 // CreateProblemList 创建团队题单
 func CreateProblemList(req *models.CreateProblemListRequest, userID uint64) (uint64, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(req.TeamID, userID, ""); err != nil {
 		return 0, err
 	}
-	if role == "" {
-		return 0, errors.New("您不是团队成员")
+	if err := checkTeamWritable(req.TeamID); err != nil {
+		return 0, err
 	}
 
 	list := &models.TeamProblemList{
@@ -540,6 +694,9 @@ func CreateProblemList(req *models.CreateProblemListRequest, userID uint64) (uin
 		if err := tx.Create(list).Error; err != nil {
 			return err
 		}
+		if err := recordTeamEvent(tx, list.TeamID, &userID, models.TeamEventProblemListCreated, list.ID, "创建了题单 "+list.Title); err != nil {
+			return err
+		}
 
 		// 添加题目
 		for _, problem := range req.Problems {
@@ -565,15 +722,15 @@ func UpdateProblemList(listID uint64, req *models.UpdateProblemListRequest, user
 		return err
 	}
 
+	if err := checkTeamWritable(list.TeamID); err != nil {
+		return err
+	}
+
 	// 检查用户权限
 	if list.CreatedBy != userID {
-		role, err := GetTeamUserRole(list.TeamID, userID)
-		if err != nil {
+		if _, err := AuthorizeTeam(list.TeamID, userID, models.TeamPermManageProblems); err != nil {
 			return err
 		}
-		if role != "owner" && role != "admin" {
-			return errors.New("权限不足")
-		}
 	}
 
 	return config.DB.Transaction(func(tx *gorm.DB) error {
@@ -613,51 +770,73 @@ func UpdateProblemList(listID uint64, req *models.UpdateProblemListRequest, user
 			}
 		}
 
-		return nil
+		title := list.Title
+		if req.Title != "" {
+			title = req.Title
+		}
+		return recordTeamEvent(tx, list.TeamID, &userID, models.TeamEventProblemListUpdated, listID, "更新了题单 "+title)
 	})
 }
 
 // UpdateTeamMemberRole 更新团队成员角色
 func UpdateTeamMemberRole(teamID uint64, targetUserID uint64, newRole string, operatorID uint64) error {
 	// 检查操作者权限
-	operatorRole, err := GetTeamUserRole(teamID, operatorID)
+	operatorRole, err := AuthorizeTeam(teamID, operatorID, models.TeamPermManageMembers)
 	if err != nil {
 		return err
 	}
-	if operatorRole != "owner" {
-		return errors.New("只有团队所有者可以修改成员角色")
-	}
 
 	// 不能修改自己的角色
 	if targetUserID == operatorID {
 		return errors.New("不能修改自己的角色")
 	}
 
-	// 验证新角色是否有效
-	validRoles := map[string]bool{"admin": true, "member": true}
-	if !validRoles[newRole] {
+	// 验证新角色是否有效，所有者只能通过转让产生
+	if newRole == models.TeamRoleOwner {
+		return errors.New("无效的角色")
+	}
+	exists, err := teamRoleExists(teamID, newRole)
+	if err != nil {
+		return err
+	}
+	if !exists {
 		return errors.New("无效的角色")
 	}
 
-	// 更新角色
-	result := config.DB.Model(&models.TeamMember{}).
-		Where("team_id = ? AND user_id = ?", teamID, targetUserID).
-		Update("role", newRole)
-
-	if result.Error != nil {
-		return result.Error
+	targetRole, err := GetTeamUserRole(teamID, targetUserID)
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
+	if targetRole == "" {
 		return errors.New("用户不是团队成员")
 	}
+	if targetRole == models.TeamRoleOwner {
+		return errors.New("不能修改团队所有者的角色")
+	}
 
-	return nil
+	// 非所有者（组织管理员除外）不能修改拥有成员管理权限的成员，也不能授予成员管理权限
+	if operatorRole != models.TeamRoleOwner && operatorRole != models.TeamRoleOrgAdmin {
+		for _, role := range []string{targetRole, newRole} {
+			canManage, err := TeamRoleHasPermission(teamID, role, models.TeamPermManageMembers)
+			if err != nil {
+				return err
+			}
+			if canManage {
+				return errors.New("只有团队所有者可以调整拥有成员管理权限的角色")
+			}
+		}
+	}
+
+	// 更新角色
+	return config.DB.Model(&models.TeamMember{}).
+		Where("team_id = ? AND user_id = ?", teamID, targetUserID).
+		Update("role", newRole).Error
 }
 
 // RemoveTeamMember 移除团队成员
 func RemoveTeamMember(teamID uint64, targetUserID uint64, operatorID uint64) error {
 	// 检查操作者权限
-	operatorRole, err := GetTeamUserRole(teamID, operatorID)
+	operatorRole, err := AuthorizeTeam(teamID, operatorID, models.TeamPermManageMembers)
 	if err != nil {
 		return err
 	}
@@ -668,23 +847,27 @@ func RemoveTeamMember(teamID uint64, targetUserID uint64, operatorID uint64) err
 		return err
 	}
 
-	// 权限检查
-	if operatorRole != "owner" {
-		if operatorRole != "admin" || targetRole == "owner" || targetRole == "admin" {
-			return errors.New("权限不足")
-		}
-	}
-
 	// 不能移除自己
 	if targetUserID == operatorID {
 		return errors.New("不能移除自己")
 	}
 
 	// 不能移除团队所有者
-	if targetRole == "owner" {
+	if targetRole == models.TeamRoleOwner {
 		return errors.New("不能移除团队所有者")
 	}
 
+	// 非所有者（组织管理员除外）不能移除拥有成员管理权限的成员
+	if operatorRole != models.TeamRoleOwner && operatorRole != models.TeamRoleOrgAdmin {
+		canManage, err := TeamRoleHasPermission(teamID, targetRole, models.TeamPermManageMembers)
+		if err != nil {
+			return err
+		}
+		if canManage {
+			return errors.New("权限不足")
+		}
+	}
+
 	// 移除成员
 	result := config.DB.Where("team_id = ? AND user_id = ?", teamID, targetUserID).Delete(&models.TeamMember{})
 	if result.Error != nil {
@@ -694,6 +877,10 @@ func RemoveTeamMember(teamID uint64, targetUserID uint64, operatorID uint64) err
 		return errors.New("用户不是团队成员")
 	}
 
+	var username string
+	config.DB.Model(&models.User{}).Select("username").Where("id = ?", targetUserID).Take(&username)
+	logTeamEvent(teamID, &operatorID, models.TeamEventMemberRemoved, targetUserID, "将成员 "+username+" 移出了团队")
+
 	return nil
 }
 
@@ -705,12 +892,14 @@ func GetAssignmentDetail(assignmentID uint64, userID uint64) (*models.TeamAssign
 	}
 
 	// 检查用户权限
-	role, err := GetTeamUserRole(assignment.TeamID, userID)
+	role, err := AuthorizeTeam(assignment.TeamID, userID, "")
 	if err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
+	if visible, err := isAssignmentVisible(&assignment, role); err != nil {
+		return nil, err
+	} else if !visible {
+		return nil, errors.New("作业不存在")
 	}
 
 	// 获取作业题目
@@ -729,17 +918,24 @@ func GetAssignmentDetail(assignmentID uint64, userID uint64) (*models.TeamAssign
 // GetAssignmentList 获取作业列表
 func GetAssignmentList(teamID uint64, userID uint64) ([]models.TeamAssignment, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(teamID, userID)
+	role, err := AuthorizeTeam(teamID, userID, "")
 	if err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
+
+	query := config.DB.Where("team_id = ?", teamID)
+
+	// 没有作业管理权限的成员只能看到已发布的作业
+	canManage, err := TeamRoleHasPermission(teamID, role, models.TeamPermCreateAssignment)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		query = query.Where("is_published = ?", true)
 	}
 
 	var assignments []models.TeamAssignment
-	if err := config.DB.Where("team_id = ?", teamID).
-		Order("created_at DESC").
+	if err := query.Order("created_at DESC").
 		Find(&assignments).Error; err != nil {
 		return nil, err
 	}
@@ -747,8 +943,8 @@ func GetAssignmentList(teamID uint64, userID uint64) ([]models.TeamAssignment, e
 	return assignments, nil
 }
 
-// GetProblemListDetail 获取题单详情
-func GetProblemListDetail(listID uint64, userID uint64) (*models.TeamProblemList, error) {
+// GetProblemListDetail 获取题单详情，登录用户同时返回各题目的完成状态和完成百分比
+func GetProblemListDetail(listID uint64, userID uint64) (*models.TeamProblemListDetail, error) {
 	var list models.TeamProblemList
 	if err := config.DB.First(&list, listID).Error; err != nil {
 		return nil, err
@@ -756,26 +952,48 @@ func GetProblemListDetail(listID uint64, userID uint64) (*models.TeamProblemList
 
 	// 检查访问权限
 	if !list.IsPublic {
-		role, err := GetTeamUserRole(list.TeamID, userID)
+		isMember, err := HasTeamPermission(list.TeamID, userID, "")
 		if err != nil {
 			return nil, err
 		}
-		if role == "" {
+		if !isMember {
 			return nil, errors.New("无权访问题单")
 		}
 	}
 
 	// 获取题单题目
-	var problems []models.TeamProblemListItem
-	if err := config.DB.Where("list_id = ?", listID).
-		Order("order_index").
-		Find(&problems).Error; err != nil {
+	items, err := getProblemListItems(listID)
+	if err != nil {
 		return nil, err
 	}
 
-	// TODO: 获取每个题目的提交状态
+	detail := &models.TeamProblemListDetail{
+		TeamProblemList: list,
+		Items:           items,
+		TotalCount:      len(items),
+	}
+	if userID == 0 || len(items) == 0 {
+		return detail, nil
+	}
+
+	// 获取当前用户每个题目的提交状态
+	progress, err := getProblemListProgress(items, []uint64{userID})
+	if err != nil {
+		return nil, err
+	}
+	for i := range detail.Items {
+		status := progress[userID].statusOf(detail.Items[i].ProblemID)
+		detail.Items[i].Status = status
+		switch status {
+		case models.ProblemProgressSolved:
+			detail.SolvedCount++
+		case models.ProblemProgressAttempted:
+			detail.AttemptedCount++
+		}
+	}
+	detail.CompletionRate = completionRate(detail.SolvedCount, detail.TotalCount)
 
-	return &list, nil
+	return detail, nil
 }
 
 // GetProblemListList 获取题单列表
@@ -783,8 +1001,11 @@ func GetProblemListList(teamID uint64, userID uint64) ([]models.TeamProblemList,
 	query := config.DB.Where("team_id = ?", teamID)
 
 	// 如果不是团队成员，只能看到公开题单
-	role, _ := GetTeamUserRole(teamID, userID)
-	if role == "" {
+	isMember, err := HasTeamPermission(teamID, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
 		query = query.Where("is_public = ?", true)
 	}
 
@@ -931,12 +1152,11 @@ func RemoveTeamAvatar(teamID uint64) error {
 // UpdateTeamNickname 更新团队内名称
 func UpdateTeamNickname(teamID uint64, userID uint64, nickname string) error {
 	// 检查用户是否是团队成员
-	role, err := GetTeamUserRole(teamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(teamID, userID, ""); err != nil {
 		return err
 	}
-	if role == "" {
-		return errors.New("您不是团队成员")
+	if err := checkTeamWritable(teamID); err != nil {
+		return err
 	}
 
 	if nickname == "" {
@@ -970,13 +1190,9 @@ func GetTeamNickname(teamID uint64, userID uint64) (string, error) {
 // GetTeamMemberList 获取团队成员列表
 func GetTeamMemberList(teamID uint64, req *models.TeamMemberListRequest, userID uint64) (*models.TeamMemberListResponse, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(teamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(teamID, userID, ""); err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
-	}
 
 	// 构建基础查询
 	query := config.DB.Table("team_members").
@@ -986,6 +1202,7 @@ func GetTeamMemberList(teamID uint64, req *models.TeamMemberListRequest, userID
 			avatars.filename as avatar,
 			team_members.role,
 			team_members.joined_at,
+			team_members.invitation_id,
 			team_nicknames.nickname
 		`).
 		Joins("LEFT JOIN users ON team_members.user_id = users.id").
@@ -1013,8 +1230,9 @@ func GetTeamMemberList(teamID uint64, req *models.TeamMemberListRequest, userID
 	// 获取分页数据
 	var members []models.TeamMemberInfo
 	offset := (req.Page - 1) * req.PageSize
+	// 内置角色按 所有者、管理员、助教、成员、旁听 排序，FIELD 为 0 的自定义角色排在最后
 	if err := query.Offset(offset).Limit(req.PageSize).
-		Order("FIELD(team_members.role, 'owner', 'admin', 'member') ASC, team_members.joined_at DESC").
+		Order("FIELD(team_members.role, 'observer', 'member', 'assistant', 'admin', 'owner') DESC, team_members.role ASC, team_members.joined_at DESC").
 		Scan(&members).Error; err != nil {
 		return nil, err
 	}
@@ -1030,13 +1248,9 @@ func GetTeamMemberList(teamID uint64, req *models.TeamMemberListRequest, userID
 // GetAvailableProblemList 获取可用题目列表
 func GetAvailableProblemList(req *models.AvailableProblemListRequest, userID uint64) (*models.AvailableProblemListResponse, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
-	if err != nil {
+	if _, err := AuthorizeTeam(req.TeamID, userID, ""); err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
-	}
 
 	var problems []models.AvailableProblemInfo
 	var total int64
@@ -1093,6 +1307,51 @@ func GetAvailableProblemList(req *models.AvailableProblemListRequest, userID uin
 		}
 	}
 
+	if req.Type == "org" {
+		// 查询团队所属组织及上级组织题库中的题目
+		orgIDs, err := getTeamOrganizationPoolIDs(req.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if len(orgIDs) > 0 {
+			orgQuery := config.DB.Table("problems").
+				Select(`
+					problems.id,
+					'org' as type,
+					0 as team_problem_id,
+					problems.title,
+					problems.time_limit,
+					problems.memory_limit,
+					'' as tags,
+					COALESCE(problems.difficulty, 'medium') as difficulty,
+					problems.created_by,
+					problems.created_at
+				`).
+				Where("problems.id IN (?)", config.DB.Table("organization_problems").
+					Select("problem_id").
+					Where("org_id IN ?", orgIDs))
+
+			if req.Keyword != "" {
+				if id, err := strconv.ParseUint(req.Keyword, 10, 64); err == nil {
+					orgQuery = orgQuery.Where("problems.id = ?", id)
+				} else {
+					orgQuery = orgQuery.Where("problems.title LIKE ?", "%"+req.Keyword+"%")
+				}
+			}
+
+			if err := orgQuery.Count(&total).Error; err != nil {
+				return nil, err
+			}
+			if err := orgQuery.
+				Offset((req.Page - 1) * req.PageSize).
+				Limit(req.PageSize).
+				Order("problems.created_at DESC").
+				Scan(&problems).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if req.Type == "global" || req.Type == "all" {
 		// 查询全局题目
 		globalQuery := config.DB.Table("problems").
@@ -1181,12 +1440,12 @@ func GetAvailableProblemList(req *models.AvailableProblemListRequest, userID uin
 // GetAssignmentProblems 获取作业题目列表
 func GetAssignmentProblems(req *models.GetAssignmentProblemsRequest, userID uint64) (*models.GetAssignmentProblemsResponse, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
+	role, err := AuthorizeTeam(req.TeamID, userID, "")
 	if err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
+	if _, err := checkAssignmentVisible(req.AssignmentID, req.TeamID, role); err != nil {
+		return nil, err
 	}
 
 	// 查询作业题目
@@ -1290,12 +1549,12 @@ func GetAssignmentProblems(req *models.GetAssignmentProblemsRequest, userID uint
 // GetAssignmentProblemDetail 获取作业题目详情
 func GetAssignmentProblemDetail(req *models.GetAssignmentProblemDetailRequest, userID uint64) (*models.AssignmentProblemFullDetail, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
+	role, err := AuthorizeTeam(req.TeamID, userID, "")
 	if err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
+	if _, err := checkAssignmentVisible(req.AssignmentID, req.TeamID, role); err != nil {
+		return nil, err
 	}
 
 	// 验证题目是否属于该作业
@@ -1444,13 +1703,10 @@ func GetAssignmentProblemDetail(req *models.GetAssignmentProblemDetailRequest, u
 // SubmitAssignmentCode 提交作业代码
 func SubmitAssignmentCode(req *models.SubmitAssignmentCodeRequest, userID uint64) (uint64, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
+	role, err := AuthorizeTeam(req.TeamID, userID, models.TeamPermSubmitAssignment)
 	if err != nil {
 		return 0, err
 	}
-	if role == "" {
-		return 0, errors.New("您不是团队成员")
-	}
 
 	// 验证题目是否属于该作业
 	var assignmentProblem models.TeamAssignmentProblem
@@ -1464,8 +1720,8 @@ func SubmitAssignmentCode(req *models.SubmitAssignmentCodeRequest, userID uint64
 	}
 
 	// 验证作业是否在进行中
-	var assignment models.TeamAssignment
-	if err := config.DB.First(&assignment, req.AssignmentID).Error; err != nil {
+	assignment, err := checkAssignmentVisible(req.AssignmentID, req.TeamID, role)
+	if err != nil {
 		return 0, err
 	}
 
@@ -1473,8 +1729,19 @@ func SubmitAssignmentCode(req *models.SubmitAssignmentCodeRequest, userID uint64
 	if now.Before(assignment.StartTime) {
 		return 0, errors.New("作业尚未开始")
 	}
-	if now.After(assignment.EndTime) {
-		return 0, errors.New("作业已结束")
+
+	// 截止时间考虑成员的延期，超过截止时间后按迟交策略处理
+	deadline, err := GetAssignmentDeadline(assignment, userID)
+	if err != nil {
+		return 0, err
+	}
+	if now.After(deadline) {
+		if !assignment.AllowLate {
+			return 0, errors.New("作业已结束")
+		}
+		if assignment.LateCutoff != nil && now.After(*assignment.LateCutoff) {
+			return 0, errors.New("已超过迟交截止时间")
+		}
 	}
 
 	// 创建提交记录
@@ -1492,6 +1759,7 @@ func SubmitAssignmentCode(req *models.SubmitAssignmentCodeRequest, userID uint64
 	if err := config.DB.Create(submission).Error; err != nil {
 		return 0, err
 	}
+	logTeamEvent(req.TeamID, &userID, models.TeamEventSubmission, submission.ID, "提交了作业 "+assignment.Title)
 
 	// TODO: 发送到判题队列
 
@@ -1501,13 +1769,10 @@ func SubmitAssignmentCode(req *models.SubmitAssignmentCodeRequest, userID uint64
 // GetAssignmentSubmissions 获取作业提交记录
 func GetAssignmentSubmissions(req *models.GetAssignmentSubmissionsRequest, userID uint64) (*models.GetAssignmentSubmissionsResponse, error) {
 	// 检查用户权限
-	role, err := GetTeamUserRole(req.TeamID, userID)
+	role, err := AuthorizeTeam(req.TeamID, userID, "")
 	if err != nil {
 		return nil, err
 	}
-	if role == "" {
-		return nil, errors.New("您不是团队成员")
-	}
 
 	// 构建查询
 	query := config.DB.Table("submissions s").
@@ -1527,15 +1792,27 @@ func GetAssignmentSubmissions(req *models.GetAssignmentSubmissionsRequest, userI
 			s.time_used,
 			s.memory_used,
 			tap.score,
+			COALESCE(sr.reviewed, false) as reviewed,
+			sr.manual_score,
 			s.created_at
 		`).
 		Joins("JOIN team_assignment_problems tap ON tap.assignment_id = s.assignment_id AND tap.problem_id = s.problem_id").
+		Joins("LEFT JOIN submission_reviews sr ON sr.submission_id = s.id").
 		Joins("JOIN users u ON u.id = s.user_id").
 		Joins("LEFT JOIN team_nicknames tn ON tn.team_id = ? AND tn.user_id = s.user_id", req.TeamID).
 		Joins("LEFT JOIN problems p ON tap.problem_type = 'global' AND p.id = s.problem_id").
 		Joins("LEFT JOIN team_problems tp ON tap.problem_type = 'team' AND tp.id = tap.team_problem_id").
 		Where("s.assignment_id = ?", req.AssignmentID)
 
+	// 没有查看全部提交权限的成员只能查看自己的提交
+	canViewAll, err := TeamRoleHasPermission(req.TeamID, role, models.TeamPermViewSubmissions)
+	if err != nil {
+		return nil, err
+	}
+	if !canViewAll {
+		query = query.Where("s.user_id = ?", userID)
+	}
+
 	// 添加筛选条件
 	if req.UserID != 0 {
 		query = query.Where("s.user_id = ?", req.UserID)
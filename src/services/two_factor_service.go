@@ -0,0 +1,434 @@
+package services
+
+import (
+	"OptiOJ/src/config"
+	"OptiOJ/src/models"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer         = "OptiOJ"
+	totpPeriod         = 30 // 验证码有效周期（秒）
+	recoveryCodeCount  = 10
+	loginChallengeTTL  = 5 * time.Minute
+	maxLoginChallenges = 5 // 每个登录挑战最多尝试的次数
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidTwoFactorCode 两步验证码错误
+var ErrInvalidTwoFactorCode = errors.New("验证码错误")
+
+// IsTwoFactorRequired 判断用户是否必须启用两步验证，管理员和持有任意网站权限的账号必须启用
+func IsTwoFactorRequired(userID uint64) (bool, error) {
+	role, err := GetAdminRole(uint(userID))
+	if err != nil {
+		return false, err
+	}
+	if role != "" {
+		return true, nil
+	}
+	permissions, err := GetUserSitePermissions(uint(userID))
+	if err != nil {
+		return false, err
+	}
+	return len(permissions) > 0, nil
+}
+
+// getUserTwoFactor 获取用户的两步验证配置，未配置时返回 nil
+func getUserTwoFactor(userID uint64) (*models.UserTwoFactor, error) {
+	var twoFactor models.UserTwoFactor
+	err := config.DB.Where("user_id = ?", userID).First(&twoFactor).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// GetTwoFactorStatus 获取用户的两步验证状态
+func GetTwoFactorStatus(userID uint64) (*models.TwoFactorStatus, error) {
+	required, err := IsTwoFactorRequired(userID)
+	if err != nil {
+		return nil, err
+	}
+	twoFactor, err := getUserTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{Required: required}
+	if twoFactor != nil && twoFactor.Enabled {
+		status.Enabled = true
+		status.EnabledAt = twoFactor.EnabledAt
+		if err := config.DB.Model(&models.UserRecoveryCode{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Count(&status.RecoveryCodesLeft).Error; err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// SetupTwoFactor 生成新的 TOTP 密钥，需调用 EnableTwoFactor 提交验证码后才会生效
+func SetupTwoFactor(userID uint64) (*models.TwoFactorSetupResponse, error) {
+	twoFactor, err := getUserTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return nil, errors.New("已启用两步验证")
+	}
+
+	var user models.User
+	if err := config.DB.Select("id", "username").First(&user, userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secret := totpEncoding.EncodeToString(raw)
+
+	if err := config.DB.Exec(`
+		INSERT INTO user_two_factors (user_id, secret, enabled)
+		VALUES (?, ?, false)
+		ON DUPLICATE KEY UPDATE
+		secret = VALUES(secret),
+		enabled = false,
+		enabled_at = NULL,
+		updated_at = CURRENT_TIMESTAMP
+	`, userID, secret).Error; err != nil {
+		return nil, err
+	}
+
+	label := url.PathEscape(totpIssuer + ":" + user.Username)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", "6")
+	query.Set("period", fmt.Sprint(totpPeriod))
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OtpauthURL: "otpauth://totp/" + label + "?" + query.Encode(),
+	}, nil
+}
+
+// EnableTwoFactor 校验验证码并启用两步验证，返回新生成的恢复码
+func EnableTwoFactor(userID uint64, code string) ([]string, error) {
+	twoFactor, err := getUserTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+	if twoFactor.Enabled {
+		return nil, errors.New("已启用两步验证")
+	}
+
+	ok, err := verifyTOTP(userID, twoFactor.Secret, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.UserTwoFactor{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"enabled":    true,
+			"enabled_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor 关闭两步验证，需要验证密码和验证码；必须启用两步验证的账号不能关闭
+func DisableTwoFactor(userID uint64, req *models.DisableTwoFactorRequest) error {
+	required, err := IsTwoFactorRequired(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errors.New("管理员和持有网站权限的账号必须启用两步验证")
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return errors.New("密码错误")
+	}
+
+	ok, err := VerifyTwoFactorCode(userID, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部作废
+func RegenerateRecoveryCodes(userID uint64, code string) ([]string, error) {
+	twoFactor, err := getUserTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, errors.New("未启用两步验证")
+	}
+
+	ok, err := verifyTOTP(userID, twoFactor.Secret, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// VerifyTwoFactorCode 校验已启用两步验证的用户提交的验证码，也接受未使用过的恢复码
+func VerifyTwoFactorCode(userID uint64, code string) (bool, error) {
+	twoFactor, err := getUserTwoFactor(userID)
+	if err != nil {
+		return false, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return false, errors.New("未启用两步验证")
+	}
+
+	ok, err := verifyTOTP(userID, twoFactor.Secret, code)
+	if err != nil || ok {
+		return ok, err
+	}
+	return useRecoveryCode(userID, code)
+}
+
+// BeginTwoFactorLogin 密码验证通过后，判断是否需要两步验证，需要时创建登录挑战；不需要时返回 nil
+func BeginTwoFactorLogin(userID uint64) (*models.TwoFactorChallenge, error) {
+	twoFactor, err := getUserTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := twoFactor != nil && twoFactor.Enabled
+	required, err := IsTwoFactorRequired(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(raw)
+	if err := config.RedisClient.Set(context.Background(), loginChallengeKey(token), userID, loginChallengeTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorChallenge{Token: token, SetupRequired: !enabled}, nil
+}
+
+// SetupTwoFactorByChallenge 必须启用两步验证但尚未绑定的账号，在登录过程中使用登录挑战生成密钥
+func SetupTwoFactorByChallenge(token string) (*models.TwoFactorSetupResponse, error) {
+	userID, err := GetLoginChallengeUser(token)
+	if err != nil {
+		return nil, err
+	}
+	return SetupTwoFactor(userID)
+}
+
+// CompleteTwoFactorLogin 校验登录挑战的验证码，通过后返回用户ID；
+// 登录过程中完成绑定的账号会同时返回新生成的恢复码
+func CompleteTwoFactorLogin(req *models.TwoFactorLoginRequest) (uint64, []string, error) {
+	ctx := context.Background()
+	userID, err := GetLoginChallengeUser(req.ChallengeToken)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 限制每个挑战的尝试次数，超过后需要重新输入密码
+	attemptsKey := loginChallengeKey(req.ChallengeToken) + ":attempts"
+	attempts, err := config.RedisClient.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		return 0, nil, err
+	}
+	config.RedisClient.Expire(ctx, attemptsKey, loginChallengeTTL)
+	if attempts > maxLoginChallenges {
+		config.RedisClient.Del(ctx, loginChallengeKey(req.ChallengeToken), attemptsKey)
+		return 0, nil, errors.New("验证码错误次数过多，请重新登录")
+	}
+
+	twoFactor, err := getUserTwoFactor(userID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var recoveryCodes []string
+	if twoFactor != nil && twoFactor.Enabled {
+		ok, err := VerifyTwoFactorCode(userID, req.Code)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !ok {
+			return 0, nil, ErrInvalidTwoFactorCode
+		}
+	} else {
+		recoveryCodes, err = EnableTwoFactor(userID, req.Code)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// 挑战只能使用一次
+	deleted, err := config.RedisClient.Del(ctx, loginChallengeKey(req.ChallengeToken), attemptsKey).Result()
+	if err != nil {
+		return 0, nil, err
+	}
+	if deleted == 0 {
+		return 0, nil, errors.New("登录验证已过期，请重新登录")
+	}
+	return userID, recoveryCodes, nil
+}
+
+// loginChallengeKey 登录挑战在 Redis 中的键
+func loginChallengeKey(token string) string {
+	return "login_challenge:" + token
+}
+
+// GetLoginChallengeUser 获取登录挑战对应的用户
+func GetLoginChallengeUser(token string) (uint64, error) {
+	userID, err := config.RedisClient.Get(context.Background(), loginChallengeKey(token)).Uint64()
+	if err != nil {
+		return 0, errors.New("登录验证已过期，请重新登录")
+	}
+	return userID, nil
+}
+
+// verifyTOTP 校验 TOTP 验证码，允许前后各一个周期的时钟误差；同一周期的验证码只能使用一次
+func verifyTOTP(userID uint64, secret string, code string) (bool, error) {
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		return false, err
+	}
+	code = strings.TrimSpace(code)
+
+	counter := uint64(time.Now().Unix()) / totpPeriod
+	for _, c := range []uint64{counter - 1, counter, counter + 1} {
+		if subtle.ConstantTimeCompare([]byte(generateTOTP(key, c)), []byte(code)) != 1 {
+			continue
+		}
+		usedKey := fmt.Sprintf("totp_used:%d:%d", userID, c)
+		return config.RedisClient.SetNX(context.Background(), usedKey, 1, 3*totpPeriod*time.Second).Result()
+	}
+	return false, nil
+}
+
+// generateTOTP 按 RFC 6238（HMAC-SHA1，6 位）计算指定周期的验证码
+func generateTOTP(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// replaceRecoveryCodes 作废旧的恢复码并生成新的恢复码
+func replaceRecoveryCodes(tx *gorm.DB, userID uint64) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.UserRecoveryCode, 0, recoveryCodeCount)
+	now := time.Now()
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := hex.EncodeToString(raw)
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		records = append(records, models.UserRecoveryCode{
+			UserID:    userID,
+			CodeHash:  hashRecoveryCode(code),
+			CreatedAt: now,
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// useRecoveryCode 使用恢复码，成功后该恢复码失效
+func useRecoveryCode(userID uint64, code string) (bool, error) {
+	result := config.DB.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// hashRecoveryCode 计算恢复码摘要，忽略大小写和首尾空白
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -49,6 +49,10 @@ func GetLoginHistory(req *models.LoginHistoryRequest) ([]models.UserLogin, int64
 		query = query.Where("login_status = ?", req.Status)
 	}
 
+	if req.IPAddress != "" {
+		query = query.Where("ip_address = ?", req.IPAddress)
+	}
+
 	// 获取总数
 	err := query.Count(&total).Error
 	if err != nil {
@@ -68,3 +72,27 @@ func GetLoginHistory(req *models.LoginHistoryRequest) ([]models.UserLogin, int64
 
 	return logins, total, nil
 }
+
+// GetLoginIPSummary 按IP汇总用户的登录记录
+func GetLoginIPSummary(userID uint) ([]models.LoginIPSummary, error) {
+	var summaries []models.LoginIPSummary
+
+	err := config.DB.Model(&models.UserLogin{}).
+		Select(`
+			ip_address,
+			COUNT(*) as login_count,
+			SUM(CASE WHEN login_status != 'success' THEN 1 ELSE 0 END) as failed_count,
+			MIN(login_time) as first_login_at,
+			MAX(login_time) as last_login_at
+		`).
+		Where("user_id = ?", userID).
+		Group("ip_address").
+		Order("last_login_at DESC").
+		Scan(&summaries).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
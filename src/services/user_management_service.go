@@ -3,9 +3,7 @@ package services
 import (
 	"OptiOJ/src/config"
 	"OptiOJ/src/models"
-	"context"
 	"errors"
-	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -102,8 +100,46 @@ func GetUserList(req *models.UserListRequest) ([]models.UserListItem, int64, err
 	return users, total, nil
 }
 
+// checkUserManageable 检查操作者能否修改、封禁或解封目标用户：
+// 管理员账号只能由超级管理员操作，也不能操作拥有自己所没有的网站权限的用户
+func checkUserManageable(operatorID uint, targetID uint) error {
+	targetRole, err := GetAdminRole(targetID)
+	if err != nil {
+		return err
+	}
+	operatorRole, err := GetAdminRole(operatorID)
+	if err != nil {
+		return err
+	}
+	if operatorRole == models.SiteRoleSuperAdmin {
+		return nil
+	}
+	if targetRole != "" {
+		return errors.New("只有超级管理员可以操作管理员账号")
+	}
+
+	targetPermissions, err := GetUserSitePermissions(targetID)
+	if err != nil {
+		return err
+	}
+	operatorPermissions, err := GetUserSitePermissions(operatorID)
+	if err != nil {
+		return err
+	}
+	for _, permission := range targetPermissions {
+		if !containsString(operatorPermissions, permission) {
+			return errors.New("不能操作拥有更高权限的用户")
+		}
+	}
+	return nil
+}
+
 // UpdateUserInfo 更新用户信息
-func UpdateUserInfo(userID uint, req *models.UserUpdateRequest) error {
+func UpdateUserInfo(userID uint, req *models.UserUpdateRequest, operatorID uint) error {
+	if err := checkUserManageable(operatorID, userID); err != nil {
+		return err
+	}
+
 	updates := make(map[string]interface{})
 
 	if req.Username != "" {
@@ -169,6 +205,10 @@ func IsUserBanned(userID uint) (bool, string, error) {
 
 // BanUser 封禁用户
 func BanUser(req *models.UserBanRequest, adminID uint) error {
+	if err := checkUserManageable(adminID, req.UserID); err != nil {
+		return err
+	}
+
 	return config.DB.Transaction(func(tx *gorm.DB) error {
 		// 创建封禁记录
 		ban := models.UserBan{
@@ -184,38 +224,19 @@ func BanUser(req *models.UserBanRequest, adminID uint) error {
 		}
 
 		// 删除用户的所有token
-		patterns := []string{"access_token:*", "refresh_token:*"}
-		for _, pattern := range patterns {
-			var cursor uint64
-			for {
-				keys, nextCursor, err := config.RedisClient.Scan(context.Background(), cursor, pattern, 100).Result()
-				if err != nil {
-					return err
-				}
-
-				for _, key := range keys {
-					userIDStr, err := config.RedisClient.Get(context.Background(), key).Result()
-					if err != nil {
-						continue
-					}
-					if userIDStr == strconv.FormatUint(uint64(req.UserID), 10) {
-						config.RedisClient.Del(context.Background(), key)
-					}
-				}
-
-				cursor = nextCursor
-				if cursor == 0 {
-					break
-				}
-			}
+		if err := LogoutAllDevices(req.UserID); err != nil {
+			return err
 		}
-
-		return nil
+		return RevokeUserAccessTokens(req.UserID)
 	})
 }
 
 // UnbanUser 解封用户
 func UnbanUser(userID uint, adminID uint) error {
+	if err := checkUserManageable(adminID, userID); err != nil {
+		return err
+	}
+
 	// 将所有该用户的活跃封禁记录设置为非活跃
 	return config.DB.Model(&models.UserBan{}).
 		Where("user_id = ? AND is_active = ?", userID, true).
@@ -4,6 +4,7 @@ import (
 	"OptiOJ/src/config"
 	"OptiOJ/src/location"
 	"OptiOJ/src/models"
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -129,6 +130,53 @@ func HashPassword(password string) (string, error) {
 	return string(hashedBytes), nil
 }
 
+// ChangePassword 修改密码，同时解除首次登录修改密码的限制
+func ChangePassword(userID uint, oldPassword string, newPassword string) error {
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return errors.New("原密码错误")
+	}
+	if oldPassword == newPassword {
+		return errors.New("新密码不能与原密码相同")
+	}
+
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"password":             hashedPassword,
+		"must_change_password": false,
+	}).Error; err != nil {
+		return err
+	}
+
+	return config.RedisClient.Del(context.Background(), passwordChangeRequiredKey(userID)).Err()
+}
+
+// passwordChangeRequiredKey 需要修改密码的用户标记
+func passwordChangeRequiredKey(userID uint) string {
+	return fmt.Sprintf("password_change_required:%d", userID)
+}
+
+// markPasswordChangeRequired 标记用户需要修改密码，标记存在期间访问令牌只能用于修改密码
+func markPasswordChangeRequired(userID uint) error {
+	return config.RedisClient.Set(context.Background(), passwordChangeRequiredKey(userID), 1, 0).Err()
+}
+
+// SyncPasswordChangeRequired 登录时根据数据库同步修改密码标记，防止 Redis 数据丢失后限制失效
+func SyncPasswordChangeRequired(user *models.User) error {
+	if !user.MustChangePassword {
+		return nil
+	}
+	return markPasswordChangeRequired(uint(user.ID))
+}
+
 // GenerateUsers 批量生成用户
 func GenerateUsers(req *models.GenerateUsersRequest) (*models.GenerateUsersResponse, error) {
 	users := make([]models.GeneratedUserInfo, 0, req.Count)
@@ -152,15 +200,16 @@ func GenerateUsers(req *models.GenerateUsersRequest) (*models.GenerateUsersRespo
 				return fmt.Errorf("密码加密失败: %v", err)
 			}
 
-			// 创建用户记录，只设置必要的字段
+			// 创建用户记录，只设置必要的字段，首次登录需修改密码
 			user := models.User{
-				Username: username,
-				Password: hashedPassword,
-				Email:    email,
+				Username:           username,
+				Password:           hashedPassword,
+				Email:              email,
+				MustChangePassword: true,
 			}
 
 			// 创建用户
-			if err := tx.Select("Username", "Password", "Email").Create(&user).Error; err != nil {
+			if err := tx.Select("Username", "Password", "Email", "MustChangePassword").Create(&user).Error; err != nil {
 				if strings.Contains(err.Error(), "duplicate") {
 					continue // 如果用户名重复，跳过当前用户
 				}
@@ -194,6 +243,12 @@ func GenerateUsers(req *models.GenerateUsersRequest) (*models.GenerateUsersRespo
 		return nil, err
 	}
 
+	for _, user := range createdUsers {
+		if err := markPasswordChangeRequired(uint(user.ID)); err != nil {
+			return nil, err
+		}
+	}
+
 	return &models.GenerateUsersResponse{
 		Users: users,
 		Total: len(users),
@@ -25,6 +25,14 @@ func GenerateVerificationCode() string {
 
 // 发送验证码到邮箱
 func SendVerificationCode(email string, code string) error {
+	if err := SendEmail(email, "验证码", "您的验证码是: "+code); err != nil {
+		return errors.New("发送验证码失败: " + err.Error())
+	}
+	return nil
+}
+
+// SendEmail 发送纯文本邮件
+func SendEmail(email string, subject string, body string) error {
 	smtpHost := config.SMTP.Host
 	smtpPort := config.SMTP.Port
 	user := config.SMTP.User
@@ -40,8 +48,8 @@ func SendVerificationCode(email string, code string) error {
 	if err := m.To(email); err != nil {
 		return errors.New("设置收件人失败: " + err.Error())
 	}
-	m.Subject("验证码")
-	m.SetBodyString("text/plain", "您的验证码是: "+code)
+	m.Subject(subject)
+	m.SetBodyString("text/plain", body)
 
 	var clientOptions []mail.Option
 	clientOptions = append(clientOptions, mail.WithSMTPAuth(mail.SMTPAuthPlain), mail.WithUsername(user), mail.WithPassword(password), mail.WithPort(smtpPort))
@@ -62,7 +70,7 @@ func SendVerificationCode(email string, code string) error {
 
 	// 发送邮件
 	if err := c.DialAndSend(m); err != nil {
-		return errors.New("发送验证码失败: " + err.Error())
+		return errors.New("发送邮件失败: " + err.Error())
 	}
 
 	return nil